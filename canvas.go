@@ -29,10 +29,12 @@ const DPI = DPMM(1 / 25.4)
 
 ////////////////////////////////////////////////////////////////
 
-// Style is the path style that defines how to draw the path. When FillColor is transparent it will not fill the path. If StrokeColor is transparent or StrokeWidth is zero, it will not stroke the path. If Dashes is an empty array, it will not draw dashes but instead a solid stroke line. FillRule determines how to fill the path when paths overlap and have certain directions (clockwise, counter clockwise).
+// Style is the path style that defines how to draw the path. When FillColor is transparent and FillPaint is nil it will not fill the path. If StrokeColor is transparent and StrokePaint is nil, or StrokeWidth is zero, it will not stroke the path. If Dashes is an empty array, it will not draw dashes but instead a solid stroke line. FillRule determines how to fill the path when paths overlap and have certain directions (clockwise, counter clockwise). When FillPaint or StrokePaint is set it is used instead of FillColor or StrokeColor respectively, which then hold the average color of the paint for renderers that do not support paints.
 type Style struct {
 	FillColor    color.RGBA
+	FillPaint    Paint
 	StrokeColor  color.RGBA
+	StrokePaint  Paint
 	StrokeWidth  float64
 	StrokeCapper Capper
 	StrokeJoiner Joiner
@@ -41,6 +43,16 @@ type Style struct {
 	FillRule
 }
 
+// HasFill returns true if the path is filled, which is the case for a paint even when its average color is transparent.
+func (style Style) HasFill() bool {
+	return style.FillPaint != nil || style.FillColor.A != 0
+}
+
+// HasStroke returns true if the path is stroked, which is the case for a paint even when its average color is transparent.
+func (style Style) HasStroke() bool {
+	return (style.StrokePaint != nil || style.StrokeColor.A != 0) && 0.0 < style.StrokeWidth
+}
+
 // DefaultStyle is the default style for paths. It fills the path with a black color.
 var DefaultStyle = Style{
 	FillColor:    Black,
@@ -217,16 +229,54 @@ func (c *Context) ShearAbout(sx, sy, x, y float64) {
 	c.view = c.view.Mul(Identity.ShearAbout(sx, sy, x, y))
 }
 
-// SetFillColor sets the color to be used for filling operations.
+// SetFillColor sets the color to be used for filling operations. Any color model is accepted, but only CMYK colors keep their model as a ColorPaint for renderers that support them, other colors are converted to 8-bit RGBA.
 func (c *Context) SetFillColor(col color.Color) {
-	r, g, b, a := col.RGBA()
-	c.Style.FillColor = color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+	c.Style.FillColor = toRGBA(col)
+	c.Style.FillPaint = nil
+	if _, ok := col.(color.CMYK); ok {
+		c.Style.FillPaint = ColorPaint{C: col}
+	}
+}
+
+// SetFillPaint sets the paint to be used for filling operations, such as an image, gradient or pattern. A nil paint clears the paint, which keeps a solid color but makes the color transparent for other paints since it held their average color.
+func (c *Context) SetFillPaint(paint Paint) {
+	if paint == nil {
+		if _, ok := c.Style.FillPaint.(ColorPaint); !ok && c.Style.FillPaint != nil {
+			c.Style.FillColor = Transparent
+		}
+		c.Style.FillPaint = nil
+		return
+	} else if p, ok := paint.(ColorPaint); ok {
+		c.SetFillColor(p.C)
+		return
+	}
+	c.Style.FillColor = paint.Color()
+	c.Style.FillPaint = paint
 }
 
-// SetStrokeColor sets the color to be used for stroking operations.
+// SetStrokeColor sets the color to be used for stroking operations. Any color model is accepted, but only CMYK colors keep their model as a ColorPaint for renderers that support them, other colors are converted to 8-bit RGBA.
 func (c *Context) SetStrokeColor(col color.Color) {
-	r, g, b, a := col.RGBA()
-	c.Style.StrokeColor = color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+	c.Style.StrokeColor = toRGBA(col)
+	c.Style.StrokePaint = nil
+	if _, ok := col.(color.CMYK); ok {
+		c.Style.StrokePaint = ColorPaint{C: col}
+	}
+}
+
+// SetStrokePaint sets the paint to be used for stroking operations, such as an image, gradient or pattern. A nil paint clears the paint, which keeps a solid color but makes the color transparent for other paints since it held their average color.
+func (c *Context) SetStrokePaint(paint Paint) {
+	if paint == nil {
+		if _, ok := c.Style.StrokePaint.(ColorPaint); !ok && c.Style.StrokePaint != nil {
+			c.Style.StrokeColor = Transparent
+		}
+		c.Style.StrokePaint = nil
+		return
+	} else if p, ok := paint.(ColorPaint); ok {
+		c.SetStrokeColor(p.C)
+		return
+	}
+	c.Style.StrokeColor = paint.Color()
+	c.Style.StrokePaint = paint
 }
 
 // SetStrokeWidth sets the width in mm for stroking operations.
@@ -312,6 +362,7 @@ func (c *Context) Close() {
 func (c *Context) Fill() {
 	style := c.Style
 	style.StrokeColor = Transparent
+	style.StrokePaint = nil
 	c.RenderPath(c.path, style, c.view)
 	c.path = &Path{}
 }
@@ -320,6 +371,7 @@ func (c *Context) Fill() {
 func (c *Context) Stroke() {
 	style := c.Style
 	style.FillColor = Transparent
+	style.FillPaint = nil
 	c.RenderPath(c.path, style, c.view)
 	c.path = &Path{}
 }
@@ -332,7 +384,7 @@ func (c *Context) FillStroke() {
 
 // DrawPath draws a path at position (x,y) using the current draw state.
 func (c *Context) DrawPath(x, y float64, paths ...*Path) {
-	if c.Style.FillColor.A == 0 && (c.Style.StrokeColor.A == 0 || c.Style.StrokeWidth == 0.0) {
+	if !c.Style.HasFill() && !c.Style.HasStroke() {
 		return
 	}
 
@@ -364,10 +416,11 @@ func (c *Context) DrawText(x, y float64, texts ...*Text) {
 
 // RenderTextAsPath renders the text converted to paths (calling r.RenderPath)
 func RenderTextAsPath(r Renderer, text *Text, m Matrix) {
-	paths, colors := text.ToPaths()
+	paths, faces := text.toPaths()
 	for i, path := range paths {
 		style := DefaultStyle
-		style.FillColor = colors[i]
+		style.FillColor = faces[i].Color
+		style.FillPaint = faces[i].Paint
 		r.RenderPath(path, style, m)
 	}
 }
@@ -401,6 +454,8 @@ type layer struct {
 type Canvas struct {
 	layers []layer
 	W, H   float64
+
+	stats *statsRecorder // only set by WriteStats
 }
 
 // New returns a new Canvas that records all drawing operations into layers. The canvas can then be rendered to any other renderer.
@@ -457,7 +512,7 @@ func (c *Canvas) Fit(margin float64) {
 		bounds := Rect{}
 		if l.path != nil {
 			bounds = l.path.Bounds()
-			if l.style.StrokeColor.A != 0 && 0.0 < l.style.StrokeWidth {
+			if l.style.HasStroke() {
 				bounds.X -= l.style.StrokeWidth / 2.0
 				bounds.Y -= l.style.StrokeWidth / 2.0
 				bounds.W += l.style.StrokeWidth
@@ -489,9 +544,11 @@ func (c *Canvas) Render(r Renderer) {
 	if viewer, ok := r.(interface{ View() Matrix }); ok {
 		view = viewer.View()
 	}
-	for _, l := range c.layers {
+	for i, l := range c.layers {
 		m := view.Mul(l.m)
-		if l.path != nil {
+		if c.stats != nil {
+			c.stats.render(r, i, l, m)
+		} else if l.path != nil {
 			r.RenderPath(l.path, l.style, m)
 		} else if l.text != nil {
 			r.RenderText(l.text, m)
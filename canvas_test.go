@@ -1,8 +1,12 @@
 package canvas
 
 import (
+	"bytes"
 	"image"
+	"image/color"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/tdewolff/test"
 )
@@ -65,3 +69,60 @@ func TestCanvasFit(t *testing.T) {
 	test.Float(t, c.W, 20)
 	test.Float(t, c.H, 20)
 }
+
+func TestCanvasHash(t *testing.T) {
+	draw := func(col color.RGBA) *Canvas {
+		c := New(10, 10)
+		ctx := NewContext(c)
+		ctx.SetFillColor(col)
+		ctx.DrawPath(0.0, 0.0, Circle(5.0))
+		ctx.DrawImage(0.0, 0.0, image.NewRGBA(image.Rect(0, 0, 2, 2)), 1.0)
+		return c
+	}
+	test.T(t, draw(Red).Hash(), draw(Red).Hash())
+	test.That(t, draw(Red).Hash() != draw(Blue).Hash())
+	test.That(t, New(10, 10).Hash() != New(10, 20).Hash())
+}
+
+func TestCanvasWriteStats(t *testing.T) {
+	dejaVuSerif := NewFontFamily("dejavu-serif")
+	dejaVuSerif.LoadFontFile("font/DejaVuSerif.ttf", FontRegular)
+	face := dejaVuSerif.Face(10.0, Black, FontRegular, FontNormal)
+
+	c := New(10, 10)
+	ctx := NewContext(c)
+	ctx.DrawPath(0.0, 0.0, MustParseSVG("M0 0L5 0Q10 5 10 10zM5 5L6 6"))
+	ctx.DrawText(0.0, 0.0, NewTextLine(face, "ﬁx", Left))
+	ctx.DrawImage(0.0, 0.0, image.NewRGBA(image.Rect(0, 0, 2, 2)), 1.0)
+
+	layers := []int{}
+	writes := 0
+	hooks := &Hooks{
+		Layer: func(i int, d time.Duration) { layers = append(layers, i) },
+		Write: func(n int, d time.Duration) { writes += n },
+	}
+	writer := func(w io.Writer, c *Canvas) error {
+		c.Render(New(c.W, c.H))
+		_, err := w.Write([]byte("canvas"))
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	stats, err := c.WriteStats(buf, writer, hooks)
+	test.Error(t, err)
+	test.String(t, buf.String(), "canvas")
+	test.T(t, stats.Layers, 3)
+	test.T(t, stats.Paths, 1)
+	test.T(t, stats.Segments, 4)
+	test.T(t, stats.Texts, 1)
+	test.T(t, stats.Glyphs, 3)
+	test.T(t, stats.Images, 1)
+	test.T(t, stats.Bytes, int64(6))
+	test.T(t, stats.Total, stats.Render+stats.Output+stats.Encode)
+	test.T(t, layers, []int{0, 1, 2})
+	test.T(t, writes, 6)
+
+	// stats are only collected when writing with WriteStats
+	c.Render(New(c.W, c.H))
+	test.T(t, c.stats == (*statsRecorder)(nil), true)
+}
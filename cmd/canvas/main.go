@@ -0,0 +1,286 @@
+// Command canvas converts raster images to PDF, XPS, SVG, EPS, TeX/PGF, TikZ, EMF, DXF, HPGL, G-code, PNG, JPG or GIF, and pages of PDF documents to PDF.
+//
+// Usage:
+//
+//	canvas [flags] input output
+//
+// The input is a page of a PDF document or a PNG, JPG or GIF image, and the output format is determined by the extension of the output filename unless -format is given. PDF input can only be written as PDF. Use - for standard input or output.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/dxf"
+	"github.com/tdewolff/canvas/emf"
+	"github.com/tdewolff/canvas/eps"
+	"github.com/tdewolff/canvas/pdf"
+	"github.com/tdewolff/canvas/plotter"
+	"github.com/tdewolff/canvas/rasterizer"
+	"github.com/tdewolff/canvas/svg"
+	"github.com/tdewolff/canvas/tex"
+	"github.com/tdewolff/canvas/xps"
+)
+
+// pageSizes are the named page sizes in millimeters.
+var pageSizes = map[string][2]float64{
+	"a3":     {297.0, 420.0},
+	"a4":     {210.0, 297.0},
+	"a5":     {148.0, 210.0},
+	"letter": {215.9, 279.4},
+	"legal":  {215.9, 355.6},
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] input output\n\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "Convert a raster image to PDF, XPS, SVG, EPS, TeX, TikZ, EMF, DXF, HPGL, G-code, PNG, JPG or GIF, or a page of a PDF document to PDF.\n\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	format := flag.String("format", "", "output format (pdf, xps, svg, eps, tex, tikz, emf, dxf, hpgl, gcode, png, jpg or gif), defaults to the output file extension")
+	dpi := flag.Float64("dpi", 96.0, "resolution in dots per inch for raster output and raster input")
+	page := flag.String("page", "", "page size as A3, A4, A5, Letter, Legal or WxH in millimeters, the input is scaled to fit")
+	margin := flag.Float64("margin", 0.0, "page margin in millimeters")
+	landscape := flag.Bool("landscape", false, "use the page size in landscape orientation")
+	pdfPage := flag.Int("pdfpage", 1, "page number of the PDF input, starting at 1")
+	background := flag.String("background", "", "background color, defaults to white for JPG output and transparent otherwise")
+	quality := flag.Int("quality", 90, "JPG quality")
+	stats := flag.Bool("stats", false, "print rendering statistics to standard error")
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), flag.Arg(1), options{
+		format:     *format,
+		resolution: canvas.DPMM(*dpi) * canvas.DPI,
+		page:       *page,
+		margin:     *margin,
+		landscape:  *landscape,
+		pdfPage:    *pdfPage,
+		background: *background,
+		quality:    *quality,
+		stats:      *stats,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "canvas: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+type options struct {
+	format     string
+	resolution canvas.DPMM
+	page       string
+	margin     float64
+	landscape  bool
+	pdfPage    int
+	background string
+	quality    int
+	stats      bool
+}
+
+func run(input, output string, opts options) error {
+	format := strings.ToLower(opts.format)
+	if format == "" {
+		format = strings.ToLower(strings.TrimPrefix(filepath.Ext(output), "."))
+		if format == "" {
+			return fmt.Errorf("unknown output format, use -format")
+		}
+	}
+
+	var writer canvas.Writer
+	switch format {
+	case "pdf":
+		writer = pdf.Writer
+	case "xps":
+		writer = xps.Writer
+	case "svg":
+		writer = svg.Writer
+	case "eps", "ps":
+		writer = eps.Writer
+	case "tex", "pgf":
+		writer = tex.Writer
+	case "tikz":
+		writer = tex.TikZWriter
+	case "emf":
+		writer = emf.Writer
+	case "dxf":
+		writer = dxf.Writer
+	case "hpgl", "plt":
+		writer = plotter.HPGLWriter(nil)
+	case "gcode", "ngc":
+		writer = plotter.GCodeWriter(nil)
+	case "png":
+		writer = rasterizer.PNGWriter(opts.resolution)
+	case "jpg", "jpeg":
+		writer = rasterizer.JPGWriter(opts.resolution, &jpeg.Options{Quality: opts.quality})
+		if opts.background == "" {
+			opts.background = "white"
+		}
+	case "gif":
+		writer = rasterizer.GIFWriter(opts.resolution, nil)
+	default:
+		return fmt.Errorf("unsupported output format %s", format)
+	}
+	opts.format = format
+
+	if opts.stats {
+		writer = statsWriter(writer)
+	}
+
+	c, err := open(input, opts)
+	if err != nil {
+		return err
+	}
+	if c, err = layout(c, opts); err != nil {
+		return err
+	}
+
+	if output == "-" {
+		return writer(os.Stdout, c)
+	}
+	return c.WriteFile(output, writer)
+}
+
+// statsWriter wraps a writer to print the rendering statistics to standard error.
+func statsWriter(writer canvas.Writer) canvas.Writer {
+	return func(w io.Writer, c *canvas.Canvas) error {
+		stats, err := c.WriteStats(w, writer, nil)
+		fmt.Fprintf(os.Stderr, "canvas: %v\n", stats)
+		return err
+	}
+}
+
+// open reads the input, which is a PDF document or a raster image. PDF documents can only be written as PDF, since other renderers rasterize the pages without their text, shadings and clipping paths.
+func open(input string, opts options) (*canvas.Canvas, error) {
+	var r io.Reader = os.Stdin
+	if input != "-" {
+		f, err := os.Open(input)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	// sniff whether the input is an image or a PDF document
+	b := make([]byte, 512)
+	n, err := io.ReadFull(r, b)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	r = io.MultiReader(bytes.NewReader(b[:n]), r)
+	if bytes.HasPrefix(b[:n], []byte("%PDF-")) {
+		if opts.format != "pdf" {
+			return nil, fmt.Errorf("%s: PDF input can only be converted to PDF, not %s", input, opts.format)
+		}
+		c, err := openPDF(r, opts.pdfPage)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", input, err)
+		}
+		return c, nil
+	} else if _, _, err := image.DecodeConfig(bytes.NewReader(b[:n])); err == image.ErrFormat {
+		return nil, fmt.Errorf("%s: unsupported input format, expected a PDF document or a PNG, JPG or GIF image", input)
+	}
+	return openImage(r, opts.resolution)
+}
+
+// openPDF reads a page of a PDF document and returns a canvas of the page size, which embeds the page when written as PDF.
+func openPDF(r io.Reader, page int) (*canvas.Canvas, error) {
+	templates, err := pdf.ReadTemplates(r)
+	if err != nil {
+		return nil, err
+	} else if page < 1 || len(templates) < page {
+		return nil, fmt.Errorf("page %d not found, document has %d pages", page, len(templates))
+	}
+
+	template := templates[page-1]
+	c := canvas.New(template.Size())
+	template.Draw(canvas.NewContext(c), 0.0, 0.0)
+	return c, nil
+}
+
+// openImage reads a raster image and returns a canvas of the image size at the given resolution.
+func openImage(r io.Reader, resolution canvas.DPMM) (*canvas.Canvas, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	size := img.Bounds().Size()
+	c := canvas.New(float64(size.X)/float64(resolution), float64(size.Y)/float64(resolution))
+	ctx := canvas.NewContext(c)
+	ctx.DrawImage(0.0, 0.0, img, float64(resolution))
+	return c, nil
+}
+
+// layout places the canvas on a page if a page size is given, and adds a background color.
+func layout(c *canvas.Canvas, opts options) (*canvas.Canvas, error) {
+	w, h := c.W, c.H
+	view := canvas.Identity
+	if opts.page != "" {
+		size, ok := pageSizes[strings.ToLower(opts.page)]
+		if !ok {
+			dims := strings.Split(strings.ToLower(opts.page), "x")
+			if len(dims) != 2 {
+				return nil, fmt.Errorf("bad page size %s", opts.page)
+			}
+			var err error
+			for i, dim := range dims {
+				if size[i], err = strconv.ParseFloat(dim, 64); err != nil || size[i] <= 0.0 {
+					return nil, fmt.Errorf("bad page size %s", opts.page)
+				}
+			}
+		}
+		if opts.landscape {
+			size[0], size[1] = size[1], size[0]
+		}
+
+		w, h = size[0], size[1]
+		if 0.0 < c.W && 0.0 < c.H {
+			scale := canvas.Point{X: (w - 2.0*opts.margin) / c.W, Y: (h - 2.0*opts.margin) / c.H}
+			s := scale.X
+			if scale.Y < s {
+				s = scale.Y
+			}
+			view = view.Translate((w-c.W*s)/2.0, (h-c.H*s)/2.0).Scale(s, s)
+		}
+	} else if opts.background == "" {
+		return c, nil
+	}
+
+	page := canvas.New(w, h)
+	if opts.background != "" {
+		col, err := canvas.ParseColor(opts.background)
+		if err != nil {
+			return nil, err
+		}
+		style := canvas.DefaultStyle
+		style.FillColor = col
+		page.RenderPath(canvas.Rectangle(w, h), style, canvas.Identity)
+	}
+	c.Render(&pageRenderer{page, view})
+	return page, nil
+}
+
+// pageRenderer is a canvas that transforms all drawing operations by the view matrix.
+type pageRenderer struct {
+	*canvas.Canvas
+	view canvas.Matrix
+}
+
+// View returns the view matrix, which is used by canvas.Canvas.Render.
+func (r *pageRenderer) View() canvas.Matrix {
+	return r.view
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/pdf"
+	"github.com/tdewolff/test"
+)
+
+func TestOpen(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	buf := &bytes.Buffer{}
+	test.Error(t, png.Encode(buf, img))
+	c, err := openImage(buf, canvas.DPMM(2.0))
+	test.Error(t, err)
+	test.Float(t, c.W, 10.0)
+	test.Float(t, c.H, 5.0)
+
+	src := canvas.New(30.0, 40.0)
+	ctx := canvas.NewContext(src)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(10.0, 10.0))
+	buf = &bytes.Buffer{}
+	test.Error(t, pdf.Writer(buf, src))
+	c, err = openPDF(bytes.NewReader(buf.Bytes()), 1)
+	test.Error(t, err)
+	test.That(t, math.Abs(c.W-30.0) < 1e-3, c.W) // page size is rounded in points
+	test.That(t, math.Abs(c.H-40.0) < 1e-3, c.H)
+
+	_, err = openPDF(bytes.NewReader(buf.Bytes()), 2)
+	test.That(t, err != nil && strings.Contains(err.Error(), "page 2 not found"), err)
+
+	// PDF input is only written as PDF
+	dir, err := ioutil.TempDir("", "canvas")
+	test.Error(t, err)
+	defer os.RemoveAll(dir)
+	input := filepath.Join(dir, "input.pdf")
+	test.Error(t, ioutil.WriteFile(input, buf.Bytes(), 0644))
+	test.Error(t, run(input, filepath.Join(dir, "output.pdf"), options{pdfPage: 1}))
+	err = run(input, filepath.Join(dir, "output.png"), options{pdfPage: 1, resolution: canvas.DPMM(1.0)})
+	test.That(t, err != nil && strings.Contains(err.Error(), "can only be converted to PDF"), err)
+}
+
+func TestLayout(t *testing.T) {
+	c := canvas.New(10.0, 20.0)
+	page, err := layout(c, options{page: "50x50", margin: 5.0, background: "#fff"})
+	test.Error(t, err)
+	test.Float(t, page.W, 50.0)
+	test.Float(t, page.H, 50.0)
+
+	_, err = layout(c, options{background: "bad"})
+	test.That(t, err != nil, "expected error for bad background color")
+	_, err = layout(c, options{page: "A0"})
+	test.That(t, err != nil, "expected error for bad page size")
+}
@@ -1,10 +1,196 @@
 package canvas
 
-import "image/color"
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
 
 // Transparent when used as a fill or stroke color will indicate that the fill or stroke will not be drawn.
 var Transparent = color.RGBA{0x00, 0x00, 0x00, 0x00} // rgba(0, 0, 0, 0)
 
+// toRGBA converts a color of any color model to premultiplied RGBA, a nil color is transparent.
+func toRGBA(col color.Color) color.RGBA {
+	if col == nil {
+		return Transparent
+	}
+	r, g, b, a := col.RGBA()
+	return color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+}
+
+// CMYK returns a CMYK color with cyan, magenta, yellow and key (black) components between 0 and 1. Renderers for print such as PDF and EPS keep the CMYK values when used as a fill or stroke color, other renderers convert it to RGB.
+func CMYK(c, m, y, k float64) color.CMYK {
+	return color.CMYK{unitToUint8(c), unitToUint8(m), unitToUint8(y), unitToUint8(k)}
+}
+
+// HSL returns the color of a hue in degrees and a saturation and lightness between 0 and 1.
+func HSL(h, s, l float64) color.RGBA {
+	return HSLA(h, s, l, 1.0)
+}
+
+// HSLA returns the color of a hue in degrees and a saturation, lightness and alpha between 0 and 1.
+func HSLA(h, s, l, a float64) color.RGBA {
+	s = math.Max(0.0, math.Min(1.0, s))
+	l = math.Max(0.0, math.Min(1.0, l))
+	chroma := (1.0 - math.Abs(2.0*l-1.0)) * s
+	return hueToRGBA(h, chroma, l-chroma/2.0, a)
+}
+
+// HSV returns the color of a hue in degrees and a saturation and value between 0 and 1.
+func HSV(h, s, v float64) color.RGBA {
+	return HSVA(h, s, v, 1.0)
+}
+
+// HSVA returns the color of a hue in degrees and a saturation, value and alpha between 0 and 1.
+func HSVA(h, s, v, a float64) color.RGBA {
+	s = math.Max(0.0, math.Min(1.0, s))
+	v = math.Max(0.0, math.Min(1.0, v))
+	chroma := v * s
+	return hueToRGBA(h, chroma, v-chroma, a)
+}
+
+// hueToRGBA returns the color of a hue in degrees with the given chroma, where m is added to all components to match the lightness or value.
+func hueToRGBA(h, chroma, m, a float64) color.RGBA {
+	h = math.Mod(h, 360.0)
+	if h < 0.0 {
+		h += 360.0
+	}
+	h /= 60.0
+	x := chroma * (1.0 - math.Abs(math.Mod(h, 2.0)-1.0))
+	var r, g, b float64
+	switch int(h) {
+	case 0:
+		r, g = chroma, x
+	case 1:
+		r, g = x, chroma
+	case 2:
+		g, b = chroma, x
+	case 3:
+		g, b = x, chroma
+	case 4:
+		r, b = x, chroma
+	default:
+		r, b = chroma, x
+	}
+	return withAlpha(color.RGBA{unitToUint8(r + m), unitToUint8(g + m), unitToUint8(b + m), 0xff}, a)
+}
+
+// Hex returns the color of a hexadecimal string such as #f00, #f008, #ff0000 or #ff000080, the # is optional. It panics if the string is not a valid hexadecimal color.
+func Hex(s string) color.RGBA {
+	col, ok := parseHex(strings.TrimPrefix(s, "#"))
+	if !ok {
+		panic(fmt.Errorf("bad hex color %q", s))
+	}
+	return col
+}
+
+// MustParseColor parses a CSS color and panics on error, see ParseColor.
+func MustParseColor(s string) color.RGBA {
+	col, err := ParseColor(s)
+	if err != nil {
+		panic(err)
+	}
+	return col
+}
+
+// ParseColor parses a CSS color, which is a color keyword such as red or transparent, a hexadecimal color such as #f00, #f008, #ff0000 or #ff000080, or an rgb(), rgba(), hsl() or hsla() function. Percentages are allowed for the components of rgb() and for the alpha component.
+func ParseColor(s string) (color.RGBA, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if col, ok := cssColors[s]; ok {
+		return col, nil
+	} else if strings.HasPrefix(s, "#") {
+		if col, ok := parseHex(s[1:]); ok {
+			return col, nil
+		}
+	} else if open := strings.IndexByte(s, '('); open != -1 && strings.HasSuffix(s, ")") {
+		args := strings.FieldsFunc(s[open+1:len(s)-1], func(r rune) bool { return r == ',' || r == ' ' || r == '/' })
+		if len(args) != 3 && len(args) != 4 {
+			return Transparent, fmt.Errorf("bad color %q", s)
+		}
+
+		var v [4]float64
+		var percent [4]bool
+		for i, arg := range args {
+			if i == 0 {
+				arg = strings.TrimSuffix(arg, "deg")
+			}
+			percent[i] = strings.HasSuffix(arg, "%")
+			var err error
+			if v[i], err = strconv.ParseFloat(strings.TrimSuffix(arg, "%"), 64); err != nil {
+				return Transparent, fmt.Errorf("bad color %q", s)
+			}
+		}
+		alpha := 1.0
+		if len(args) == 4 {
+			alpha = v[3]
+			if percent[3] {
+				alpha /= 100.0
+			}
+		}
+
+		switch s[:open] {
+		case "rgb", "rgba":
+			var rgb [3]uint8
+			for i := range rgb {
+				if percent[i] {
+					rgb[i] = unitToUint8(v[i] / 100.0)
+				} else {
+					rgb[i] = uint8(math.Max(0.0, math.Min(255.0, v[i]+0.5)))
+				}
+			}
+			return withAlpha(color.RGBA{rgb[0], rgb[1], rgb[2], 0xff}, alpha), nil
+		case "hsl", "hsla":
+			return HSLA(v[0], v[1]/100.0, v[2]/100.0, alpha), nil
+		}
+	}
+	return Transparent, fmt.Errorf("bad color %q", s)
+}
+
+// parseHex parses a hexadecimal color of 3, 4, 6 or 8 digits without the leading #.
+func parseHex(hex string) (color.RGBA, bool) {
+	if len(hex) == 3 || len(hex) == 4 {
+		long := make([]byte, 0, 2*len(hex))
+		for i := 0; i < len(hex); i++ {
+			long = append(long, hex[i], hex[i])
+		}
+		hex = string(long)
+	}
+	if len(hex) != 6 && len(hex) != 8 {
+		return Transparent, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Transparent, false
+	}
+	a := uint64(0xff)
+	if len(hex) == 8 {
+		a = v & 0xff
+		v >>= 8
+	}
+	return withAlpha(color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}, float64(a)/255.0), true
+}
+
+// withAlpha multiplies the premultiplied color by alpha.
+func withAlpha(col color.RGBA, alpha float64) color.RGBA {
+	alpha = math.Max(0.0, math.Min(1.0, alpha))
+	if alpha == 1.0 {
+		return col
+	}
+	return color.RGBA{
+		uint8(float64(col.R)*alpha + 0.5),
+		uint8(float64(col.G)*alpha + 0.5),
+		uint8(float64(col.B)*alpha + 0.5),
+		uint8(float64(col.A)*alpha + 0.5),
+	}
+}
+
+// unitToUint8 converts a value between 0 and 1 to a value between 0 and 255.
+func unitToUint8(v float64) uint8 {
+	return uint8(math.Max(0.0, math.Min(1.0, v))*255.0 + 0.5)
+}
+
 // from https://golang.org/x/image/colornames
 var (
 	Aliceblue            = color.RGBA{0xf0, 0xf8, 0xff, 0xff} // rgb(240, 248, 255)
@@ -155,3 +341,155 @@ var (
 	Yellow               = color.RGBA{0xff, 0xff, 0x00, 0xff} // rgb(255, 255, 0)
 	Yellowgreen          = color.RGBA{0x9a, 0xcd, 0x32, 0xff} // rgb(154, 205, 50)
 )
+
+// cssColors are the CSS color keywords.
+var cssColors = map[string]color.RGBA{
+	"transparent":          Transparent,
+	"aliceblue":            Aliceblue,
+	"antiquewhite":         Antiquewhite,
+	"aqua":                 Aqua,
+	"aquamarine":           Aquamarine,
+	"azure":                Azure,
+	"beige":                Beige,
+	"bisque":               Bisque,
+	"black":                Black,
+	"blanchedalmond":       Blanchedalmond,
+	"blue":                 Blue,
+	"blueviolet":           Blueviolet,
+	"brown":                Brown,
+	"burlywood":            Burlywood,
+	"cadetblue":            Cadetblue,
+	"chartreuse":           Chartreuse,
+	"chocolate":            Chocolate,
+	"coral":                Coral,
+	"cornflowerblue":       Cornflowerblue,
+	"cornsilk":             Cornsilk,
+	"crimson":              Crimson,
+	"cyan":                 Cyan,
+	"darkblue":             Darkblue,
+	"darkcyan":             Darkcyan,
+	"darkgoldenrod":        Darkgoldenrod,
+	"darkgray":             Darkgray,
+	"darkgreen":            Darkgreen,
+	"darkgrey":             Darkgrey,
+	"darkkhaki":            Darkkhaki,
+	"darkmagenta":          Darkmagenta,
+	"darkolivegreen":       Darkolivegreen,
+	"darkorange":           Darkorange,
+	"darkorchid":           Darkorchid,
+	"darkred":              Darkred,
+	"darksalmon":           Darksalmon,
+	"darkseagreen":         Darkseagreen,
+	"darkslateblue":        Darkslateblue,
+	"darkslategray":        Darkslategray,
+	"darkslategrey":        Darkslategrey,
+	"darkturquoise":        Darkturquoise,
+	"darkviolet":           Darkviolet,
+	"deeppink":             Deeppink,
+	"deepskyblue":          Deepskyblue,
+	"dimgray":              Dimgray,
+	"dimgrey":              Dimgrey,
+	"dodgerblue":           Dodgerblue,
+	"firebrick":            Firebrick,
+	"floralwhite":          Floralwhite,
+	"forestgreen":          Forestgreen,
+	"fuchsia":              Fuchsia,
+	"gainsboro":            Gainsboro,
+	"ghostwhite":           Ghostwhite,
+	"gold":                 Gold,
+	"goldenrod":            Goldenrod,
+	"gray":                 Gray,
+	"green":                Green,
+	"greenyellow":          Greenyellow,
+	"grey":                 Grey,
+	"honeydew":             Honeydew,
+	"hotpink":              Hotpink,
+	"indianred":            Indianred,
+	"indigo":               Indigo,
+	"ivory":                Ivory,
+	"khaki":                Khaki,
+	"lavender":             Lavender,
+	"lavenderblush":        Lavenderblush,
+	"lawngreen":            Lawngreen,
+	"lemonchiffon":         Lemonchiffon,
+	"lightblue":            Lightblue,
+	"lightcoral":           Lightcoral,
+	"lightcyan":            Lightcyan,
+	"lightgoldenrodyellow": Lightgoldenrodyellow,
+	"lightgray":            Lightgray,
+	"lightgreen":           Lightgreen,
+	"lightgrey":            Lightgrey,
+	"lightpink":            Lightpink,
+	"lightsalmon":          Lightsalmon,
+	"lightseagreen":        Lightseagreen,
+	"lightskyblue":         Lightskyblue,
+	"lightslategray":       Lightslategray,
+	"lightslategrey":       Lightslategrey,
+	"lightsteelblue":       Lightsteelblue,
+	"lightyellow":          Lightyellow,
+	"lime":                 Lime,
+	"limegreen":            Limegreen,
+	"linen":                Linen,
+	"magenta":              Magenta,
+	"maroon":               Maroon,
+	"mediumaquamarine":     Mediumaquamarine,
+	"mediumblue":           Mediumblue,
+	"mediumorchid":         Mediumorchid,
+	"mediumpurple":         Mediumpurple,
+	"mediumseagreen":       Mediumseagreen,
+	"mediumslateblue":      Mediumslateblue,
+	"mediumspringgreen":    Mediumspringgreen,
+	"mediumturquoise":      Mediumturquoise,
+	"mediumvioletred":      Mediumvioletred,
+	"midnightblue":         Midnightblue,
+	"mintcream":            Mintcream,
+	"mistyrose":            Mistyrose,
+	"moccasin":             Moccasin,
+	"navajowhite":          Navajowhite,
+	"navy":                 Navy,
+	"oldlace":              Oldlace,
+	"olive":                Olive,
+	"olivedrab":            Olivedrab,
+	"orange":               Orange,
+	"orangered":            Orangered,
+	"orchid":               Orchid,
+	"palegoldenrod":        Palegoldenrod,
+	"palegreen":            Palegreen,
+	"paleturquoise":        Paleturquoise,
+	"palevioletred":        Palevioletred,
+	"papayawhip":           Papayawhip,
+	"peachpuff":            Peachpuff,
+	"peru":                 Peru,
+	"pink":                 Pink,
+	"plum":                 Plum,
+	"powderblue":           Powderblue,
+	"purple":               Purple,
+	"red":                  Red,
+	"rosybrown":            Rosybrown,
+	"royalblue":            Royalblue,
+	"saddlebrown":          Saddlebrown,
+	"salmon":               Salmon,
+	"sandybrown":           Sandybrown,
+	"seagreen":             Seagreen,
+	"seashell":             Seashell,
+	"sienna":               Sienna,
+	"silver":               Silver,
+	"skyblue":              Skyblue,
+	"slateblue":            Slateblue,
+	"slategray":            Slategray,
+	"slategrey":            Slategrey,
+	"snow":                 Snow,
+	"springgreen":          Springgreen,
+	"steelblue":            Steelblue,
+	"tan":                  Tan,
+	"teal":                 Teal,
+	"thistle":              Thistle,
+	"tomato":               Tomato,
+	"turquoise":            Turquoise,
+	"violet":               Violet,
+	"wheat":                Wheat,
+	"white":                White,
+	"whitesmoke":           Whitesmoke,
+	"yellow":               Yellow,
+	"yellowgreen":          Yellowgreen,
+}
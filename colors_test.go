@@ -0,0 +1,79 @@
+package canvas
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+func TestParseColor(t *testing.T) {
+	var tts = []struct {
+		s   string
+		col color.RGBA
+	}{
+		{"red", Red},
+		{" Transparent ", Transparent},
+		{"#00f", Blue},
+		{"#f008", color.RGBA{0x88, 0x00, 0x00, 0x88}},
+		{"#00FF00", Lime},
+		{"#FF000080", color.RGBA{0x80, 0x00, 0x00, 0x80}},
+		{"rgb(0, 128, 0)", Green},
+		{"rgb(0 128 0 / 50%)", color.RGBA{0x00, 0x40, 0x00, 0x80}},
+		{"rgba(100%,0%,0%,0.5)", color.RGBA{0x80, 0x00, 0x00, 0x80}},
+		{"hsl(120, 100%, 25%)", Green},
+		{"hsla(240deg, 100%, 50%, 1)", Blue},
+	}
+	for _, tt := range tts {
+		t.Run(tt.s, func(t *testing.T) {
+			col, err := ParseColor(tt.s)
+			test.Error(t, err)
+			test.T(t, col, tt.col)
+		})
+	}
+
+	for _, s := range []string{"", "redish", "#12", "#12345g", "rgb(1,2)", "rgb(a,b,c)", "cmyk(0,0,0,0)"} {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParseColor(s)
+			test.That(t, err != nil, "expected error")
+		})
+	}
+}
+
+func TestColorConstructors(t *testing.T) {
+	test.T(t, Hex("#ff0000"), Red)
+	test.T(t, Hex("00f"), Blue)
+	test.T(t, HSL(0.0, 1.0, 0.5), Red)
+	test.T(t, HSL(-240.0, 1.0, 0.5), Lime)
+	test.T(t, HSL(0.0, 0.0, 1.0), White)
+	test.T(t, HSLA(240.0, 1.0, 0.5, 0.5), color.RGBA{0x00, 0x00, 0x80, 0x80})
+	test.T(t, HSV(60.0, 1.0, 1.0), Yellow)
+	test.T(t, HSV(300.0, 1.0, 1.0), Fuchsia)
+	test.T(t, HSVA(180.0, 1.0, 1.0, 0.0), Transparent)
+	test.T(t, CMYK(0.0, 1.0, 1.0, 0.0), color.CMYK{0x00, 0xff, 0xff, 0x00})
+	test.T(t, CMYK(-1.0, 0.5, 2.0, 0.0), color.CMYK{0x00, 0x80, 0xff, 0x00})
+}
+
+func TestContextColorModels(t *testing.T) {
+	c := New(10.0, 10.0)
+	ctx := NewContext(c)
+
+	ctx.SetFillColor(color.NRGBA{0xff, 0x00, 0x00, 0x80})
+	test.T(t, ctx.Style.FillColor, color.RGBA{0x80, 0x00, 0x00, 0x80})
+	test.T(t, ctx.Style.FillPaint, nil)
+
+	ctx.SetFillColor(color.Gray16{0xffff})
+	test.T(t, ctx.Style.FillColor, White)
+
+	cmyk := CMYK(0.0, 1.0, 1.0, 0.0)
+	ctx.SetFillColor(cmyk)
+	test.T(t, ctx.Style.FillColor, Red)
+	test.T(t, ctx.Style.FillPaint, Paint(ColorPaint{C: cmyk}))
+
+	ctx.SetStrokePaint(ColorPaint{C: cmyk})
+	test.T(t, ctx.Style.StrokeColor, Red)
+	test.T(t, ctx.Style.StrokePaint, Paint(ColorPaint{C: cmyk}))
+
+	ctx.SetStrokeColor(Blue)
+	test.T(t, ctx.Style.StrokePaint, nil)
+}
@@ -0,0 +1,290 @@
+package draw2d
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"path/filepath"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dbase"
+	"github.com/tdewolff/canvas"
+)
+
+const ptPerMm = 72.0 / 25.4
+
+// Draw2D is a github.com/llgcode/draw2d graphic context. Coordinates are in millimeters with the origin in the top-left corner and the Y axis pointing down, as is the convention for draw2d. Font sizes are in points and scaled by the DPI, which is 92 by default just like for draw2dimg.
+type Draw2D struct {
+	*draw2dbase.StackGraphicContext
+	ctx   *canvas.Context
+	dpi   int
+	fonts map[draw2d.FontData]*canvas.FontFamily
+}
+
+var _ draw2d.GraphicContext = &Draw2D{}
+
+// NewDraw2D returns a new github.com/llgcode/draw2d graphic context.
+func NewDraw2D(r canvas.Renderer) *Draw2D {
+	return &Draw2D{
+		StackGraphicContext: draw2dbase.NewStackGraphicContext(),
+		ctx:                 canvas.NewContext(r),
+		dpi:                 92,
+		fonts:               map[draw2d.FontData]*canvas.FontFamily{},
+	}
+}
+
+// SetFontFamily sets the font family to be used for the given font data. By default, fonts are loaded from draw2d's font folder (see draw2d.SetFontFolder and draw2d.SetFontNamer), falling back to the system fonts.
+func (gc *Draw2D) SetFontFamily(fontData draw2d.FontData, family *canvas.FontFamily) {
+	gc.fonts[fontData] = family
+}
+
+// view returns the transformation matrix from draw2d coordinates to the renderer's coordinates, including the current transformation matrix.
+func (gc *Draw2D) view() canvas.Matrix {
+	tr := gc.Current.Tr
+	m := canvas.Matrix{{tr[0], tr[2], tr[4]}, {tr[1], tr[3], tr[5]}}
+	return canvas.Identity.Translate(0.0, gc.ctx.Height()).ReflectY().Mul(m)
+}
+
+// style returns the path style of the current state. Since draw2d strokes paths before applying the current transformation matrix, the line width and dashes are scaled by it.
+func (gc *Draw2D) style() canvas.Style {
+	scale := math.Sqrt(math.Abs(gc.Current.Tr.Determinant()))
+	style := canvas.DefaultStyle
+	style.FillColor = toRGBA(gc.Current.FillColor)
+	style.StrokeColor = toRGBA(gc.Current.StrokeColor)
+	style.StrokeWidth = gc.Current.LineWidth * scale
+	switch gc.Current.Cap {
+	case draw2d.RoundCap:
+		style.StrokeCapper = canvas.RoundCap
+	case draw2d.SquareCap:
+		style.StrokeCapper = canvas.SquareCap
+	default:
+		style.StrokeCapper = canvas.ButtCap
+	}
+	switch gc.Current.Join {
+	case draw2d.RoundJoin:
+		style.StrokeJoiner = canvas.RoundJoin
+	case draw2d.BevelJoin:
+		style.StrokeJoiner = canvas.BevelJoin
+	default:
+		style.StrokeJoiner = canvas.MiterJoin
+	}
+	style.DashOffset = gc.Current.DashOffset * scale
+	style.Dashes = make([]float64, len(gc.Current.Dash))
+	for i, dash := range gc.Current.Dash {
+		style.Dashes[i] = dash * scale
+	}
+	if gc.Current.FillRule == draw2d.FillRuleWinding {
+		style.FillRule = canvas.NonZero
+	} else {
+		style.FillRule = canvas.EvenOdd
+	}
+	return style
+}
+
+// toPath converts draw2d paths to a path.
+func toPath(paths []*draw2d.Path) *canvas.Path {
+	p := &canvas.Path{}
+	for _, path := range paths {
+		i := 0
+		for _, cmp := range path.Components {
+			switch cmp {
+			case draw2d.MoveToCmp:
+				p.MoveTo(path.Points[i], path.Points[i+1])
+				i += 2
+			case draw2d.LineToCmp:
+				p.LineTo(path.Points[i], path.Points[i+1])
+				i += 2
+			case draw2d.QuadCurveToCmp:
+				p.QuadTo(path.Points[i], path.Points[i+1], path.Points[i+2], path.Points[i+3])
+				i += 4
+			case draw2d.CubicCurveToCmp:
+				p.CubeTo(path.Points[i], path.Points[i+1], path.Points[i+2], path.Points[i+3], path.Points[i+4], path.Points[i+5])
+				i += 6
+			case draw2d.ArcToCmp:
+				// the line towards the start of the arc has already been added
+				rx, ry := path.Points[i+2], path.Points[i+3]
+				startAngle := path.Points[i+4] * 180.0 / math.Pi
+				angle := path.Points[i+5] * 180.0 / math.Pi
+				p.Arc(rx, ry, 0.0, startAngle, startAngle+angle)
+				i += 6
+			case draw2d.CloseCmp:
+				p.Close()
+			}
+		}
+	}
+	return p
+}
+
+func (gc *Draw2D) draw(style canvas.Style, paths []*draw2d.Path) {
+	paths = append(paths, gc.Current.Path)
+	gc.ctx.Push()
+	gc.ctx.SetView(gc.view())
+	gc.ctx.Style = style
+	gc.ctx.DrawPath(0.0, 0.0, toPath(paths))
+	gc.ctx.Pop()
+	gc.Current.Path.Clear()
+}
+
+// Stroke strokes the paths and the current path with the current stroke color.
+func (gc *Draw2D) Stroke(paths ...*draw2d.Path) {
+	style := gc.style()
+	style.FillColor = canvas.Transparent
+	gc.draw(style, paths)
+}
+
+// Fill fills the paths and the current path with the current fill color.
+func (gc *Draw2D) Fill(paths ...*draw2d.Path) {
+	style := gc.style()
+	style.StrokeColor = canvas.Transparent
+	gc.draw(style, paths)
+}
+
+// FillStroke fills and then strokes the paths and the current path.
+func (gc *Draw2D) FillStroke(paths ...*draw2d.Path) {
+	gc.draw(gc.style(), paths)
+}
+
+// DrawImage draws the raster image at its bounds in the current coordinate system, where one pixel equals one unit.
+func (gc *Draw2D) DrawImage(img image.Image) {
+	bounds := img.Bounds()
+	gc.ctx.Push()
+	gc.ctx.SetView(gc.view().Translate(float64(bounds.Min.X), float64(bounds.Max.Y)).ReflectY())
+	gc.ctx.DrawImage(0.0, 0.0, img, 1.0)
+	gc.ctx.Pop()
+}
+
+// Clear removes everything that has been drawn if the renderer is a Canvas, otherwise it does nothing.
+func (gc *Draw2D) Clear() {
+	if c, ok := gc.ctx.Renderer.(*canvas.Canvas); ok {
+		c.Reset()
+	}
+}
+
+// ClearRect fills the rectangle with white, since vector output cannot erase what has been drawn before.
+func (gc *Draw2D) ClearRect(x1, y1, x2, y2 int) {
+	style := canvas.DefaultStyle
+	style.FillColor = canvas.White
+	gc.ctx.Push()
+	gc.ctx.SetView(gc.view())
+	gc.ctx.Style = style
+	gc.ctx.DrawPath(float64(x1), float64(y1), canvas.Rectangle(float64(x2-x1), float64(y2-y1)))
+	gc.ctx.Pop()
+}
+
+// SetDPI sets the resolution used to scale font sizes.
+func (gc *Draw2D) SetDPI(dpi int) {
+	gc.dpi = dpi
+}
+
+// GetDPI returns the resolution used to scale font sizes.
+func (gc *Draw2D) GetDPI() int {
+	return gc.dpi
+}
+
+func (gc *Draw2D) fontFace(col color.Color) (canvas.FontFace, bool) {
+	fontData := gc.Current.FontData
+	style := canvas.FontRegular
+	if fontData.Style&draw2d.FontStyleBold != 0 {
+		style |= canvas.FontBold
+	}
+	if fontData.Style&draw2d.FontStyleItalic != 0 {
+		style |= canvas.FontItalic
+	}
+
+	family, ok := gc.fonts[fontData]
+	if !ok {
+		family = canvas.NewFontFamily(fontData.Name)
+		if err := family.LoadFontFile(filepath.Join(draw2d.GetFontFolder(), draw2d.FontFileName(fontData)), style); err != nil {
+			if err := family.LoadLocalFont(fontData.Name, style); err != nil {
+				family = nil
+			}
+		}
+		gc.fonts[fontData] = family
+	}
+	if family == nil {
+		return canvas.FontFace{}, false
+	}
+	size := gc.Current.FontSize * float64(gc.dpi) / 72.0
+	return family.Face(size*ptPerMm, col, style, canvas.FontNormal), true
+}
+
+// textPath returns the outline of the text with its baseline starting at (x,y), and the advance width.
+func (gc *Draw2D) textPath(s string, x, y float64) (*canvas.Path, float64) {
+	face, ok := gc.fontFace(canvas.Black)
+	if !ok {
+		return &canvas.Path{}, 0.0
+	}
+	p, width := face.ToPath(s)
+	return p.Transform(canvas.Identity.Translate(x, y).ReflectY()), width
+}
+
+// GetStringBounds returns the bounds of the text, where the left edge of the first character and the baseline are at (0,0).
+func (gc *Draw2D) GetStringBounds(s string) (left, top, right, bottom float64) {
+	p, _ := gc.textPath(s, 0.0, 0.0)
+	bounds := p.Bounds()
+	return bounds.X, bounds.Y, bounds.X + bounds.W, bounds.Y + bounds.H
+}
+
+// CreateStringPath adds the outline of the text with its baseline starting at (x,y) to the current path, and returns the advance width.
+func (gc *Draw2D) CreateStringPath(s string, x, y float64) (cursor float64) {
+	p, width := gc.textPath(s, x, y)
+	p.ReplaceArcs().Iterate(func(_, end canvas.Point) {
+		gc.MoveTo(end.X, end.Y)
+	}, func(_, end canvas.Point) {
+		gc.LineTo(end.X, end.Y)
+	}, func(_, cp, end canvas.Point) {
+		gc.QuadCurveTo(cp.X, cp.Y, end.X, end.Y)
+	}, func(_, cp1, cp2, end canvas.Point) {
+		gc.CubicCurveTo(cp1.X, cp1.Y, cp2.X, cp2.Y, end.X, end.Y)
+	}, func(_ canvas.Point, _, _, _ float64, _, _ bool, end canvas.Point) {
+		gc.LineTo(end.X, end.Y)
+	}, func(_, _ canvas.Point) {
+		gc.Close()
+	})
+	return width
+}
+
+// FillString draws the text with its baseline starting at (0,0) using the current fill color, and returns the advance width.
+func (gc *Draw2D) FillString(text string) (cursor float64) {
+	return gc.FillStringAt(text, 0.0, 0.0)
+}
+
+// FillStringAt draws the text with its baseline starting at (x,y) using the current fill color, and returns the advance width.
+func (gc *Draw2D) FillStringAt(text string, x, y float64) (cursor float64) {
+	face, ok := gc.fontFace(gc.Current.FillColor)
+	if !ok {
+		return 0.0
+	}
+	gc.ctx.Push()
+	gc.ctx.SetView(gc.view().Translate(x, y).ReflectY())
+	gc.ctx.DrawText(0.0, 0.0, canvas.NewTextLine(face, text, canvas.Left))
+	gc.ctx.Pop()
+	return face.TextWidth(text)
+}
+
+// StrokeString strokes the outline of the text with its baseline starting at (0,0), and returns the advance width.
+func (gc *Draw2D) StrokeString(text string) (cursor float64) {
+	return gc.StrokeStringAt(text, 0.0, 0.0)
+}
+
+// StrokeStringAt strokes the outline of the text with its baseline starting at (x,y), and returns the advance width.
+func (gc *Draw2D) StrokeStringAt(text string, x, y float64) (cursor float64) {
+	p, width := gc.textPath(text, x, y)
+	style := gc.style()
+	style.FillColor = canvas.Transparent
+	style.Dashes = nil
+	gc.ctx.Push()
+	gc.ctx.SetView(gc.view())
+	gc.ctx.Style = style
+	gc.ctx.DrawPath(0.0, 0.0, p)
+	gc.ctx.Pop()
+	return width
+}
+
+func toRGBA(col color.Color) color.RGBA {
+	if col == nil {
+		return canvas.Transparent
+	}
+	r, g, b, a := col.RGBA()
+	return color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+}
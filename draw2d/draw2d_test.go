@@ -0,0 +1,147 @@
+package draw2d
+
+import (
+	"image"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/recorder"
+	"github.com/tdewolff/test"
+)
+
+func TestDraw2DPath(t *testing.T) {
+	c := canvas.New(10.0, 10.0)
+	gc := NewDraw2D(c)
+	gc.MoveTo(1.0, 2.0)
+	gc.LineTo(5.0, 2.0)
+	gc.QuadCurveTo(5.0, 4.0, 3.0, 4.0)
+	gc.CubicCurveTo(2.0, 4.0, 1.0, 3.0, 1.0, 2.0)
+	gc.Close()
+	gc.Fill()
+
+	// the Y axis points down
+	cmds := recorder.Record(c).Commands
+	test.T(t, len(cmds), 1)
+	test.T(t, cmds[0].Path.Transform(cmds[0].M), canvas.MustParseSVG("M1 8L5 8Q5 6 3 6C2 6 1 7 1 8z"))
+
+	// the current path is cleared after drawing
+	gc.Fill()
+	test.T(t, len(recorder.Record(c).Commands), 1)
+}
+
+func TestDraw2DTransform(t *testing.T) {
+	c := canvas.New(10.0, 10.0)
+	gc := NewDraw2D(c)
+	gc.Translate(1.0, 1.0)
+	gc.Scale(2.0, 2.0)
+	gc.MoveTo(0.0, 0.0)
+	gc.LineTo(1.0, 0.0)
+	gc.LineTo(1.0, 1.0)
+	gc.Close()
+	gc.Fill()
+
+	gc.Save()
+	gc.SetMatrixTransform(draw2d.NewIdentityMatrix())
+	gc.MoveTo(0.0, 0.0)
+	gc.LineTo(1.0, 0.0)
+	gc.LineTo(1.0, 1.0)
+	gc.Close()
+	gc.Fill()
+	gc.Restore()
+
+	cmds := recorder.Record(c).Commands
+	test.T(t, len(cmds), 2)
+	test.T(t, cmds[0].Path.Transform(cmds[0].M).Bounds(), canvas.Rect{X: 1.0, Y: 7.0, W: 2.0, H: 2.0})
+	test.T(t, cmds[1].Path.Transform(cmds[1].M).Bounds(), canvas.Rect{X: 0.0, Y: 9.0, W: 1.0, H: 1.0})
+}
+
+func TestDraw2DStyle(t *testing.T) {
+	c := canvas.New(10.0, 10.0)
+	gc := NewDraw2D(c)
+	gc.SetFillColor(canvas.Red)
+	gc.SetStrokeColor(canvas.Blue)
+	gc.SetLineWidth(0.5)
+	gc.SetLineCap(draw2d.SquareCap)
+	gc.SetLineJoin(draw2d.BevelJoin)
+	gc.SetLineDash([]float64{1.0, 2.0}, 0.5)
+	gc.SetFillRule(draw2d.FillRuleEvenOdd)
+	gc.MoveTo(0.0, 0.0)
+	gc.LineTo(8.0, 0.0)
+	gc.FillStroke()
+
+	style := recorder.Record(c).Commands[0].Style
+	test.T(t, style.FillColor, canvas.Red)
+	test.T(t, style.StrokeColor, canvas.Blue)
+	test.Float(t, style.StrokeWidth, 0.5)
+	test.T(t, style.StrokeCapper, canvas.SquareCap)
+	test.T(t, style.StrokeJoiner, canvas.BevelJoin)
+	test.Float(t, style.DashOffset, 0.5)
+	test.T(t, style.Dashes, []float64{1.0, 2.0})
+	test.T(t, style.FillRule, canvas.EvenOdd)
+
+	// stroke only or fill only
+	gc.MoveTo(0.0, 0.0)
+	gc.LineTo(8.0, 0.0)
+	gc.Stroke()
+	test.T(t, recorder.Record(c).Commands[1].Style.FillColor, canvas.Transparent)
+	gc.MoveTo(0.0, 0.0)
+	gc.LineTo(8.0, 0.0)
+	gc.Fill()
+	test.T(t, recorder.Record(c).Commands[2].Style.StrokeColor, canvas.Transparent)
+
+	// the line width and dashes are in user coordinates and scaled by the transformation
+	gc.Scale(4.0, 4.0)
+	gc.MoveTo(0.0, 0.0)
+	gc.LineTo(8.0, 0.0)
+	gc.Stroke()
+	style = recorder.Record(c).Commands[3].Style
+	test.Float(t, style.StrokeWidth, 2.0)
+	test.Float(t, style.DashOffset, 2.0)
+	test.T(t, style.Dashes, []float64{4.0, 8.0})
+}
+
+func TestDraw2DText(t *testing.T) {
+	family := canvas.NewFontFamily("dejavu-serif")
+	test.Error(t, family.LoadFontFile("../font/DejaVuSerif.ttf", canvas.FontRegular))
+	fontData := draw2d.FontData{Name: "dejavu-serif", Family: draw2d.FontFamilySerif}
+
+	c := canvas.New(100.0, 100.0)
+	gc := NewDraw2D(c)
+	gc.SetFontFamily(fontData, family)
+	gc.SetFontData(fontData)
+	gc.SetFontSize(12.0)
+	gc.SetDPI(72)
+	gc.SetFillColor(canvas.Red)
+	width := gc.FillStringAt("text", 10.0, 20.0)
+
+	cmds := recorder.Record(c).Commands
+	test.T(t, len(cmds), 1)
+	test.That(t, cmds[0].Text != nil, "expected text")
+	var face canvas.FontFace
+	cmds[0].Text.WalkSpans(func(y, dx float64, span canvas.TextSpan) {
+		face = span.Face
+	})
+	test.Float(t, face.Size, 12.0) // one point equals one unit at 72 DPI
+	test.T(t, face.Color, canvas.Red)
+	test.Float(t, width, face.TextWidth("text"))
+
+	// the baseline is at (x,y) with the Y axis pointing down
+	pos := cmds[0].M.Dot(canvas.Point{})
+	test.T(t, pos, canvas.Point{X: 10.0, Y: 80.0})
+
+	left, top, right, bottom := gc.GetStringBounds("text")
+	test.That(t, left < right && top < -8.0 && bottom < 0.5, "unexpected string bounds", left, top, right, bottom) // ascent above the baseline, Y axis points down
+}
+
+func TestDraw2DImage(t *testing.T) {
+	c := canvas.New(10.0, 10.0)
+	gc := NewDraw2D(c)
+	gc.Translate(1.0, 1.0)
+	gc.DrawImage(image.NewRGBA(image.Rect(0, 0, 2, 3)))
+
+	cmds := recorder.Record(c).Commands
+	test.T(t, len(cmds), 1)
+	test.T(t, cmds[0].M.Dot(canvas.Point{}), canvas.Point{X: 1.0, Y: 6.0})
+	test.T(t, cmds[0].M.Dot(canvas.Point{X: 2.0, Y: 3.0}), canvas.Point{X: 3.0, Y: 9.0})
+}
@@ -0,0 +1,11 @@
+module github.com/tdewolff/canvas/draw2d
+
+go 1.13
+
+replace github.com/tdewolff/canvas => ../
+
+require (
+	github.com/llgcode/draw2d v0.0.0-20200603164053-19660b984a28
+	github.com/tdewolff/canvas v0.0.0-00010101000000-000000000000
+	github.com/tdewolff/test v1.0.6
+)
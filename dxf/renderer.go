@@ -0,0 +1,295 @@
+package dxf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/tdewolff/canvas"
+)
+
+// aciColors are the standard colors of the AutoCAD Color Index, index 7 is displayed as black or white depending on the background.
+var aciColors = []struct {
+	index int
+	color color.RGBA
+}{
+	{1, color.RGBA{255, 0, 0, 255}},
+	{2, color.RGBA{255, 255, 0, 255}},
+	{3, color.RGBA{0, 255, 0, 255}},
+	{4, color.RGBA{0, 255, 255, 255}},
+	{5, color.RGBA{0, 0, 255, 255}},
+	{6, color.RGBA{255, 0, 255, 255}},
+	{7, color.RGBA{0, 0, 0, 255}},
+	{8, color.RGBA{128, 128, 128, 255}},
+	{9, color.RGBA{192, 192, 192, 255}},
+	{250, color.RGBA{51, 51, 51, 255}},
+	{251, color.RGBA{80, 80, 80, 255}},
+	{252, color.RGBA{105, 105, 105, 255}},
+	{253, color.RGBA{130, 130, 130, 255}},
+	{254, color.RGBA{190, 190, 190, 255}},
+	{255, color.RGBA{255, 255, 255, 255}},
+}
+
+// DXF is a renderer that writes AutoCAD Drawing Exchange Format (R12) files for CAD software, laser cutters and CNC machines. Paths are written as polylines in millimeters, where Bézier curves are flattened and circular arcs are kept as polyline bulges. Filled paths are written as their outline and stroked paths as their (dashed) center line, since DXF has no notion of fills or stroke widths. Each entity is colored by the nearest color of the AutoCAD Color Index and images are written as their outline. R12 has no drawing units, all coordinates are in millimeters.
+type DXF struct {
+	w             io.Writer
+	width, height float64
+	layers        []string
+	layer         string
+	entities      *bytes.Buffer
+}
+
+// New creates a DXF renderer. All entities are placed on layer "0" unless SetLayer is called. Close must be called to write the file.
+func New(w io.Writer, width, height float64) *DXF {
+	return &DXF{
+		w:        w,
+		width:    width,
+		height:   height,
+		layers:   []string{"0"},
+		layer:    "0",
+		entities: &bytes.Buffer{},
+	}
+}
+
+// SetLayer sets the layer name of the entities that are rendered next, such as cut and engrave layers for laser cutters. Characters that are invalid in DXF layer names are replaced by underscores.
+func (r *DXF) SetLayer(name string) {
+	name = strings.Map(func(c rune) rune {
+		if c < 0x20 || strings.ContainsRune(`<>/\":;?*|=,`+"`", c) {
+			return '_'
+		}
+		return c
+	}, strings.TrimSpace(name))
+	if name == "" {
+		name = "0"
+	}
+	for _, layer := range r.layers {
+		if strings.EqualFold(layer, name) {
+			r.layer = layer
+			return
+		}
+	}
+	r.layers = append(r.layers, name)
+	r.layer = name
+}
+
+// Close writes the header, the layer table and the entities.
+func (r *DXF) Close() error {
+	b := &bytes.Buffer{}
+	writeGroup(b, 0, "SECTION")
+	writeGroup(b, 2, "HEADER")
+	writeGroup(b, 9, "$ACADVER")
+	writeGroup(b, 1, "AC1009")
+	writeGroup(b, 9, "$EXTMIN")
+	writeGroup(b, 10, 0.0)
+	writeGroup(b, 20, 0.0)
+	writeGroup(b, 9, "$EXTMAX")
+	writeGroup(b, 10, r.width)
+	writeGroup(b, 20, r.height)
+	writeGroup(b, 0, "ENDSEC")
+
+	writeGroup(b, 0, "SECTION")
+	writeGroup(b, 2, "TABLES")
+	writeGroup(b, 0, "TABLE")
+	writeGroup(b, 2, "LTYPE")
+	writeGroup(b, 70, 1)
+	writeGroup(b, 0, "LTYPE")
+	writeGroup(b, 2, "CONTINUOUS")
+	writeGroup(b, 70, 0)
+	writeGroup(b, 3, "Solid line")
+	writeGroup(b, 72, 65)
+	writeGroup(b, 73, 0)
+	writeGroup(b, 40, 0.0)
+	writeGroup(b, 0, "ENDTAB")
+	writeGroup(b, 0, "TABLE")
+	writeGroup(b, 2, "LAYER")
+	writeGroup(b, 70, len(r.layers))
+	for _, layer := range r.layers {
+		writeGroup(b, 0, "LAYER")
+		writeGroup(b, 2, layer)
+		writeGroup(b, 70, 0)
+		writeGroup(b, 62, 7)
+		writeGroup(b, 6, "CONTINUOUS")
+	}
+	writeGroup(b, 0, "ENDTAB")
+	writeGroup(b, 0, "ENDSEC")
+
+	writeGroup(b, 0, "SECTION")
+	writeGroup(b, 2, "ENTITIES")
+	b.Write(r.entities.Bytes())
+	writeGroup(b, 0, "ENDSEC")
+	writeGroup(b, 0, "EOF")
+
+	_, err := r.w.Write(b.Bytes())
+	return err
+}
+
+// Size returns the size of the canvas in millimeters.
+func (r *DXF) Size() (float64, float64) {
+	return r.width, r.height
+}
+
+// RenderPath renders a path to the canvas using a style and a transformation matrix.
+func (r *DXF) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix) {
+	fill := style.HasFill()
+	stroke := style.HasStroke()
+	if !fill && !stroke {
+		return
+	}
+
+	col := style.FillColor
+	path = path.Transform(m)
+	if stroke {
+		col = style.StrokeColor
+		if 0 < len(style.Dashes) {
+			path = path.Dash(style.DashOffset, style.Dashes...)
+		}
+	}
+
+	for _, p := range path.Split() {
+		vertices, bulges, closed := polyline(p)
+		if len(vertices) < 2 {
+			continue
+		}
+
+		flags := 0
+		if closed {
+			flags = 1
+		}
+		writeGroup(r.entities, 0, "POLYLINE")
+		writeGroup(r.entities, 8, r.layer)
+		writeGroup(r.entities, 62, aci(col))
+		writeGroup(r.entities, 66, 1)
+		writeGroup(r.entities, 10, 0.0)
+		writeGroup(r.entities, 20, 0.0)
+		writeGroup(r.entities, 30, 0.0)
+		writeGroup(r.entities, 70, flags)
+		for i, vertex := range vertices {
+			writeGroup(r.entities, 0, "VERTEX")
+			writeGroup(r.entities, 8, r.layer)
+			writeGroup(r.entities, 10, vertex.X)
+			writeGroup(r.entities, 20, vertex.Y)
+			writeGroup(r.entities, 30, 0.0)
+			if bulges[i] != 0.0 {
+				writeGroup(r.entities, 42, bulges[i])
+			}
+		}
+		writeGroup(r.entities, 0, "SEQEND")
+		writeGroup(r.entities, 8, r.layer)
+	}
+}
+
+// RenderText renders a text object to the canvas using a transformation matrix.
+func (r *DXF) RenderText(text *canvas.Text, m canvas.Matrix) {
+	canvas.RenderTextAsPath(r, text, m)
+}
+
+// RenderImage renders the outline of an image using a transformation matrix, since R12 files can not contain or reference images.
+func (r *DXF) RenderImage(img image.Image, m canvas.Matrix) {
+	size := img.Bounds().Size()
+	if size.X == 0 || size.Y == 0 {
+		return
+	}
+	r.RenderPath(canvas.Rectangle(float64(size.X), float64(size.Y)), canvas.Style{FillColor: canvas.Black}, m)
+}
+
+// polyline returns the vertices of a subpath, the bulge of the segment that starts at each vertex, and whether the subpath is closed. Béziers and elliptical arcs are flattened, circular arcs are converted to bulges which are the tangent of a quarter of the arc's angle, positive for counter clockwise arcs.
+func polyline(p *canvas.Path) ([]canvas.Point, []float64, bool) {
+	vertices := []canvas.Point{}
+	bulges := []float64{}
+	closed := false
+	lineTo := func(end canvas.Point) {
+		if 0 < len(vertices) && vertices[len(vertices)-1].Equals(end) {
+			return
+		}
+		vertices = append(vertices, end)
+		bulges = append(bulges, 0.0)
+	}
+	flatten := func(q *canvas.Path) {
+		for _, coord := range q.Flatten().Coords()[1:] {
+			lineTo(coord)
+		}
+	}
+	p.Iterate(func(start, end canvas.Point) {
+		vertices = append(vertices, end)
+		bulges = append(bulges, 0.0)
+	}, func(start, end canvas.Point) {
+		lineTo(end)
+	}, func(start, cp, end canvas.Point) {
+		flatten((&canvas.Path{}).MoveTo(start.X, start.Y).QuadTo(cp.X, cp.Y, end.X, end.Y))
+	}, func(start, cp1, cp2, end canvas.Point) {
+		flatten((&canvas.Path{}).MoveTo(start.X, start.Y).CubeTo(cp1.X, cp1.Y, cp2.X, cp2.Y, end.X, end.Y))
+	}, func(start canvas.Point, rx, ry, rot float64, large, sweep bool, end canvas.Point) {
+		if !canvas.Equal(rx, ry) {
+			flatten((&canvas.Path{}).MoveTo(start.X, start.Y).ArcTo(rx, ry, rot, large, sweep, end.X, end.Y))
+			return
+		}
+
+		theta := 2.0 * math.Asin(math.Min(1.0, end.Sub(start).Length()/(2.0*rx)))
+		if large {
+			theta = 2.0*math.Pi - theta
+		}
+		bulge := math.Tan(theta / 4.0)
+		if !sweep {
+			bulge = -bulge
+		}
+		bulges[len(bulges)-1] = bulge
+		lineTo(end)
+	}, func(start, end canvas.Point) {
+		closed = true
+	})
+
+	// the closing segment is implicit
+	if closed && 1 < len(vertices) && vertices[0].Equals(vertices[len(vertices)-1]) {
+		vertices = vertices[:len(vertices)-1]
+		bulges = bulges[:len(bulges)-1]
+	}
+	return vertices, bulges, closed
+}
+
+// aci returns the index of the nearest color in the AutoCAD Color Index.
+func aci(col color.RGBA) int {
+	if col.A != 0 && col.A != 255 {
+		// un-premultiply
+		col.R = uint8(float64(col.R) * 255.0 / float64(col.A))
+		col.G = uint8(float64(col.G) * 255.0 / float64(col.A))
+		col.B = uint8(float64(col.B) * 255.0 / float64(col.A))
+	}
+
+	index, min := 7, math.Inf(1)
+	for _, c := range aciColors {
+		dr := float64(col.R) - float64(c.color.R)
+		dg := float64(col.G) - float64(c.color.G)
+		db := float64(col.B) - float64(c.color.B)
+		if d := 0.3*dr*dr + 0.59*dg*dg + 0.11*db*db; d < min {
+			index, min = c.index, d
+		}
+	}
+	return index
+}
+
+// writeGroup writes a group code and its value.
+func writeGroup(w io.Writer, code int, val interface{}) {
+	switch v := val.(type) {
+	case float64:
+		val = num(v)
+	}
+	fmt.Fprintf(w, "%3d\n%v\n", code, val)
+}
+
+type num float64
+
+func (f num) String() string {
+	s := strconv.FormatFloat(float64(f), 'f', canvas.Precision, 64)
+	if strings.IndexByte(s, '.') != -1 {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
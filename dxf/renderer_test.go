@@ -0,0 +1,86 @@
+package dxf
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func TestDXF(t *testing.T) {
+	c := canvas.New(100, 80)
+	ctx := canvas.NewContext(c)
+	ctx.DrawPath(10, 10, canvas.Rectangle(20, 10))
+
+	buf := &bytes.Buffer{}
+	test.Error(t, Writer(buf, c))
+	out := buf.String()
+	test.That(t, strings.HasPrefix(out, "  0\nSECTION\n  2\nHEADER\n  9\n$ACADVER\n  1\nAC1009\n"), "bad header")
+	test.That(t, strings.Contains(out, "  9\n$EXTMAX\n 10\n100\n 20\n80\n"), "bad extents")
+	test.That(t, strings.Contains(out, "  0\nLAYER\n  2\n0\n"), "missing default layer")
+	test.That(t, strings.HasSuffix(out, "  0\nENDSEC\n  0\nEOF\n"), "bad end of file")
+	test.T(t, strings.Count(out, "POLYLINE"), 1)
+	test.T(t, strings.Count(out, "VERTEX"), 4)
+	test.That(t, strings.Contains(out, " 62\n7\n 66\n1\n 10\n0\n 20\n0\n 30\n0\n 70\n1\n"), "expected closed black polyline")
+	test.That(t, strings.Contains(out, "  0\nVERTEX\n  8\n0\n 10\n30\n 20\n20\n 30\n0\n"), "expected vertex at (30,20)")
+	test.That(t, !strings.Contains(out, "$INSUNITS"), "R12 does not define $INSUNITS")
+}
+
+func TestDXFImage(t *testing.T) {
+	buf := &bytes.Buffer{}
+	dxf := New(buf, 100, 100)
+	dxf.RenderImage(image.NewRGBA(image.Rect(0, 0, 4, 2)), canvas.Identity.Translate(10, 10).Scale(0.5, 0.5))
+	test.Error(t, dxf.Close())
+	out := buf.String()
+	test.T(t, strings.Count(out, "POLYLINE"), 1)
+	test.That(t, strings.Contains(out, "  0\nVERTEX\n  8\n0\n 10\n12\n 20\n11\n 30\n0\n"), "expected image outline corner at (12,11)")
+}
+
+func TestDXFLayers(t *testing.T) {
+	buf := &bytes.Buffer{}
+	dxf := New(buf, 100, 100)
+	dxf.SetLayer("cut")
+	dxf.RenderPath(canvas.Circle(10), canvas.Style{StrokeColor: canvas.Red, StrokeWidth: 0.1}, canvas.Identity.Translate(50, 50))
+	dxf.SetLayer("engrave/1")
+	dxf.RenderPath(canvas.Ellipse(10, 5), canvas.Style{FillColor: canvas.Blue}, canvas.Identity)
+	dxf.SetLayer("CUT")
+	dxf.RenderPath(canvas.Rectangle(10, 10), canvas.Style{}, canvas.Identity) // invisible
+	test.Error(t, dxf.Close())
+	out := buf.String()
+
+	test.That(t, strings.Contains(out, "  2\nLAYER\n 70\n3\n"), "expected three layers")
+	test.That(t, strings.Contains(out, "  0\nLAYER\n  2\ncut\n"), "missing cut layer")
+	test.That(t, strings.Contains(out, "  0\nLAYER\n  2\nengrave_1\n"), "missing engrave layer")
+	test.T(t, strings.Count(out, "POLYLINE"), 2)
+
+	// circle is written as two vertices with semicircle bulges
+	test.That(t, strings.Contains(out, "  0\nPOLYLINE\n  8\ncut\n 62\n1\n"), "expected red polyline on cut layer")
+	test.That(t, strings.Contains(out, "  0\nVERTEX\n  8\ncut\n 10\n60\n 20\n50\n 30\n0\n 42\n1\n  0\nVERTEX\n  8\ncut\n 10\n40\n 20\n50\n 30\n0\n 42\n1\n  0\nSEQEND\n"), "expected circle bulges")
+
+	// ellipse is flattened
+	test.That(t, strings.Contains(out, "  0\nPOLYLINE\n  8\nengrave_1\n 62\n5\n"), "expected blue polyline on engrave layer")
+	test.That(t, 4 < strings.Count(out, "  8\nengrave_1\n 10\n"), "expected flattened ellipse")
+}
+
+func TestDXFPolyline(t *testing.T) {
+	p := &canvas.Path{}
+	p.MoveTo(0, 0)
+	p.LineTo(10, 0)
+	p.ArcTo(5, 5, 0, false, false, 10, 10)
+	p.LineTo(0, 10)
+	vertices, bulges, closed := polyline(p)
+	test.T(t, vertices, []canvas.Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}})
+	test.T(t, len(bulges), 4)
+	test.Float(t, bulges[1], -1.0)
+	test.That(t, !closed)
+
+	test.T(t, aci(canvas.Red), 1)
+	test.T(t, aci(canvas.Black), 7)
+	test.T(t, aci(canvas.White), 255)
+	test.T(t, aci(canvas.Darkgray), 254)
+	test.T(t, num(-0.0000000001).String(), "0")
+	test.T(t, num(1.5).String(), "1.5")
+}
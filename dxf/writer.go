@@ -0,0 +1,15 @@
+package dxf
+
+import (
+	"io"
+
+	"github.com/tdewolff/canvas"
+)
+
+// Writer writes the canvas as a DXF file with all entities on layer "0".
+// Be aware that DXF does not support fills, stroke widths, images or transparency.
+func Writer(w io.Writer, c *canvas.Canvas) error {
+	dxf := New(w, c.W, c.H)
+	c.Render(dxf)
+	return dxf.Close()
+}
@@ -0,0 +1,392 @@
+package emf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+
+	"github.com/tdewolff/canvas"
+)
+
+// unitsPerMm is the number of logical units per millimeter, the reference device has a resolution of one pixel per 0.01 millimeter.
+const unitsPerMm = 100.0
+
+// record types, see [MS-EMF] 2.1.1
+const (
+	emrHeader                     = 1
+	emrPolyBezierTo               = 5
+	emrEOF                        = 14
+	emrSetPolyFillMode            = 19
+	emrMoveToEx                   = 27
+	emrSaveDC                     = 33
+	emrRestoreDC                  = 34
+	emrSetWorldTransform          = 35
+	emrSelectObject               = 37
+	emrCreateBrushIndirect        = 39
+	emrDeleteObject               = 40
+	emrLineTo                     = 54
+	emrSetMiterLimit              = 58
+	emrBeginPath                  = 59
+	emrEndPath                    = 60
+	emrCloseFigure                = 61
+	emrFillPath                   = 62
+	emrStrokeAndFillPath          = 63
+	emrStrokePath                 = 64
+	emrStretchDIBits              = 81
+	emrExtCreatePen               = 95
+	stockNullBrush         uint32 = 0x80000005
+	stockNullPen           uint32 = 0x80000008
+)
+
+// pen styles, see [MS-EMF] 2.1.25
+const (
+	psUserStyle   = 0x00000007
+	psEndcapRound = 0x00000000
+	psEndcapSq    = 0x00000100
+	psEndcapFlat  = 0x00000200
+	psJoinRound   = 0x00000000
+	psJoinBevel   = 0x00001000
+	psJoinMiter   = 0x00002000
+	psGeometric   = 0x00010000
+)
+
+// object handles, handle zero is reserved
+const (
+	brushHandle = 1
+	penHandle   = 2
+)
+
+type pen struct {
+	style  uint32
+	width  uint32
+	color  color.RGBA
+	dashes []uint32
+}
+
+// EMF is a renderer that writes Enhanced Metafiles, which can be inserted into Microsoft Word and PowerPoint as vector graphics. Paths are written as GDI paths in units of 0.01 millimeter, where elliptical arcs are converted to cubic Béziers. Text is converted to paths. Be aware that EMF does not support transparency of colors, and stroke settings that GDI does not support are drawn as filled paths.
+type EMF struct {
+	w             io.Writer
+	width, height float64
+	buf           *bytes.Buffer
+	records       uint32
+
+	brush    *color.RGBA
+	pen      *pen
+	fillRule canvas.FillRule
+	miter    uint32
+}
+
+// New creates an Enhanced Metafile renderer. Close must be called to write the file.
+func New(w io.Writer, width, height float64) *EMF {
+	return &EMF{
+		w:        w,
+		width:    width,
+		height:   height,
+		buf:      &bytes.Buffer{},
+		fillRule: canvas.EvenOdd, // GDI's default is ALTERNATE
+		miter:    10,
+	}
+}
+
+// Close writes the header and the records.
+func (r *EMF) Close() error {
+	r.record(emrEOF, uint32(0), uint32(16), uint32(20))
+
+	w, h := int32(math.Ceil(r.width*unitsPerMm)), int32(math.Ceil(r.height*unitsPerMm))
+	header := &bytes.Buffer{}
+	size := uint32(108)
+	write(header, uint32(emrHeader), size)
+	write(header, int32(0), int32(0), w-1, h-1) // bounds in device units, inclusive
+	write(header, int32(0), int32(0), w, h)     // frame in 0.01 millimeter
+	write(header, uint32(0x464D4520), uint32(0x00010000))
+	write(header, size+uint32(r.buf.Len()), r.records+1)
+	write(header, uint16(3), uint16(0))             // handles, reserved
+	write(header, uint32(0), uint32(0), uint32(0))  // description, palette
+	write(header, int32(100000), int32(100000))     // reference device in pixels
+	write(header, int32(1000), int32(1000))         // reference device in millimeters
+	write(header, uint32(0), uint32(0), uint32(0))  // pixel format, OpenGL
+	write(header, uint32(1000000), uint32(1000000)) // reference device in micrometers
+
+	if _, err := r.w.Write(header.Bytes()); err != nil {
+		return err
+	}
+	_, err := r.w.Write(r.buf.Bytes())
+	return err
+}
+
+func write(w io.Writer, vals ...interface{}) {
+	for _, val := range vals {
+		binary.Write(w, binary.LittleEndian, val)
+	}
+}
+
+// record writes a record of the given type, its size is computed from the values.
+func (r *EMF) record(typ uint32, vals ...interface{}) {
+	b := &bytes.Buffer{}
+	write(b, vals...)
+	for b.Len()%4 != 0 {
+		b.WriteByte(0)
+	}
+	write(r.buf, typ, uint32(8+b.Len()))
+	r.buf.Write(b.Bytes())
+	r.records++
+}
+
+// point returns the logical coordinates of a point, with the origin at the top-left.
+func (r *EMF) point(p canvas.Point) [2]int32 {
+	return [2]int32{int32(math.Round(p.X * unitsPerMm)), int32(math.Round((r.height - p.Y) * unitsPerMm))}
+}
+
+// bounds returns the bounding rectangle of a path in logical coordinates.
+func (r *EMF) bounds(path *canvas.Path) [4]int32 {
+	rect := path.Bounds()
+	p0 := r.point(canvas.Point{X: rect.X, Y: rect.Y + rect.H})
+	p1 := r.point(canvas.Point{X: rect.X + rect.W, Y: rect.Y})
+	return [4]int32{p0[0], p0[1], p1[0], p1[1]}
+}
+
+func colorRef(col color.RGBA) [4]uint8 {
+	if col.A != 0 && col.A != 255 {
+		// un-premultiply
+		A := float64(col.A) / 255.0
+		col.R = uint8(math.Round(float64(col.R) / A))
+		col.G = uint8(math.Round(float64(col.G) / A))
+		col.B = uint8(math.Round(float64(col.B) / A))
+	}
+	return [4]uint8{col.R, col.G, col.B, 0}
+}
+
+func (r *EMF) Size() (float64, float64) {
+	return r.width, r.height
+}
+
+func (r *EMF) setBrush(col *color.RGBA) {
+	if col == nil && r.brush == nil || col != nil && r.brush != nil && *col == *r.brush {
+		return
+	}
+
+	if r.brush != nil {
+		r.record(emrSelectObject, stockNullBrush)
+		r.record(emrDeleteObject, uint32(brushHandle))
+	}
+	r.brush = col
+	if col != nil {
+		r.record(emrCreateBrushIndirect, uint32(brushHandle), uint32(0), colorRef(*col), uint32(0)) // BS_SOLID
+		r.record(emrSelectObject, uint32(brushHandle))
+	}
+}
+
+func (r *EMF) setPen(p *pen) {
+	if p == nil && r.pen == nil || p != nil && r.pen != nil && p.style == r.pen.style && p.width == r.pen.width && p.color == r.pen.color && uint32sEqual(p.dashes, r.pen.dashes) {
+		return
+	}
+
+	if r.pen != nil {
+		r.record(emrSelectObject, stockNullPen)
+		r.record(emrDeleteObject, uint32(penHandle))
+	}
+	r.pen = p
+	if p != nil {
+		// no brush bitmap (offBmi, cbBmi, offBits, cbBits), solid brush without hatch
+		r.record(emrExtCreatePen, uint32(penHandle), uint32(0), uint32(0), uint32(0), uint32(0), p.style, p.width, uint32(0), colorRef(p.color), uint32(0), uint32(len(p.dashes)), p.dashes)
+		r.record(emrSelectObject, uint32(penHandle))
+	}
+}
+
+func (r *EMF) setFillRule(fillRule canvas.FillRule) {
+	if fillRule != r.fillRule {
+		mode := uint32(2) // WINDING
+		if fillRule == canvas.EvenOdd {
+			mode = 1 // ALTERNATE
+		}
+		r.record(emrSetPolyFillMode, mode)
+		r.fillRule = fillRule
+	}
+}
+
+func (r *EMF) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix) {
+	if style.FillPaint != nil || style.StrokePaint != nil {
+		canvas.RenderPaintAsImage(r, path, style, m)
+		return
+	}
+
+	fill := style.HasFill()
+	stroke := style.HasStroke()
+	if path.Empty() || !fill && !stroke {
+		return
+	}
+	path = path.Transform(m)
+
+	strokeUnsupported := style.DashOffset != 0.0
+	if _, ok := style.StrokeJoiner.(canvas.ArcsJoiner); ok {
+		strokeUnsupported = true
+	} else if miter, ok := style.StrokeJoiner.(canvas.MiterJoiner); ok {
+		if math.IsNaN(miter.Limit) {
+			strokeUnsupported = true
+		} else if _, ok := miter.GapJoiner.(canvas.BevelJoiner); !ok {
+			strokeUnsupported = true
+		}
+	}
+
+	if fill {
+		r.setBrush(&style.FillColor)
+		r.setFillRule(style.FillRule)
+	} else {
+		r.setBrush(nil)
+	}
+
+	if stroke && !strokeUnsupported {
+		p := &pen{
+			style: psGeometric,
+			width: uint32(math.Round(style.StrokeWidth * unitsPerMm)),
+			color: style.StrokeColor,
+		}
+		if _, ok := style.StrokeCapper.(canvas.RoundCapper); ok {
+			p.style |= psEndcapRound
+		} else if _, ok := style.StrokeCapper.(canvas.SquareCapper); ok {
+			p.style |= psEndcapSq
+		} else if _, ok := style.StrokeCapper.(canvas.ButtCapper); ok {
+			p.style |= psEndcapFlat
+		} else {
+			panic("EMF: line cap not support")
+		}
+		if _, ok := style.StrokeJoiner.(canvas.BevelJoiner); ok {
+			p.style |= psJoinBevel
+		} else if _, ok := style.StrokeJoiner.(canvas.RoundJoiner); ok {
+			p.style |= psJoinRound
+		} else if miter, ok := style.StrokeJoiner.(canvas.MiterJoiner); ok {
+			p.style |= psJoinMiter
+			if limit := uint32(math.Max(1.0, math.Round(miter.Limit*2.0/style.StrokeWidth))); limit != r.miter {
+				r.record(emrSetMiterLimit, limit)
+				r.miter = limit
+			}
+		} else {
+			panic("EMF: line join not support")
+		}
+		if 0 < len(style.Dashes) {
+			p.style |= psUserStyle
+			for _, dash := range style.Dashes {
+				p.dashes = append(p.dashes, uint32(math.Round(dash*unitsPerMm)))
+			}
+			if len(p.dashes)%2 == 1 {
+				p.dashes = append(p.dashes, p.dashes...)
+			}
+		}
+		r.setPen(p)
+	} else {
+		r.setPen(nil)
+	}
+
+	if fill || !strokeUnsupported {
+		r.writePath(path)
+		if fill && stroke && !strokeUnsupported {
+			r.record(emrStrokeAndFillPath, r.bounds(path))
+		} else if fill {
+			r.record(emrFillPath, r.bounds(path))
+		} else {
+			r.record(emrStrokePath, r.bounds(path))
+		}
+	}
+
+	if stroke && strokeUnsupported {
+		// stroke settings unsupported by EMF, draw stroke explicitly
+		if 0 < len(style.Dashes) {
+			path = path.Dash(style.DashOffset, style.Dashes...)
+		}
+		path = path.Stroke(style.StrokeWidth, style.StrokeCapper, style.StrokeJoiner)
+		r.setBrush(&style.StrokeColor)
+		r.setFillRule(canvas.NonZero)
+		r.setPen(nil)
+		r.writePath(path)
+		r.record(emrFillPath, r.bounds(path))
+	}
+}
+
+func (r *EMF) writePath(path *canvas.Path) {
+	r.record(emrBeginPath)
+	path.ReplaceArcs().Iterate(func(start, end canvas.Point) {
+		r.record(emrMoveToEx, r.point(end))
+	}, func(start, end canvas.Point) {
+		r.record(emrLineTo, r.point(end))
+	}, func(start, cp, end canvas.Point) {
+		cp1 := start.Interpolate(cp, 2.0/3.0)
+		cp2 := end.Interpolate(cp, 2.0/3.0)
+		r.record(emrPolyBezierTo, [4]int32{}, uint32(3), r.point(cp1), r.point(cp2), r.point(end))
+	}, func(start, cp1, cp2, end canvas.Point) {
+		r.record(emrPolyBezierTo, [4]int32{}, uint32(3), r.point(cp1), r.point(cp2), r.point(end))
+	}, func(start canvas.Point, rx, ry, rot float64, large, sweep bool, end canvas.Point) {
+		// arcs have been replaced
+	}, func(start, end canvas.Point) {
+		r.record(emrCloseFigure)
+	})
+	r.record(emrEndPath)
+}
+
+func (r *EMF) RenderText(text *canvas.Text, m canvas.Matrix) {
+	// TODO: (EMF) write text natively
+	canvas.RenderTextAsPath(r, text, m)
+}
+
+func (r *EMF) RenderImage(img image.Image, m canvas.Matrix) {
+	// TODO: (EMF) use EMR_ALPHABLEND for transparent images
+	size := img.Bounds().Size()
+	if size.X == 0 || size.Y == 0 {
+		return
+	}
+
+	// bottom-up DIB with 32 bits per pixel in BGRA order, composited onto white
+	rgba := image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+	draw.Draw(rgba, rgba.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Over)
+	bits := make([]byte, 0, 4*size.X*size.Y)
+	for y := size.Y - 1; 0 <= y; y-- {
+		for x := 0; x < size.X; x++ {
+			i := rgba.PixOffset(x, y)
+			bits = append(bits, rgba.Pix[i+2], rgba.Pix[i+1], rgba.Pix[i], 0)
+		}
+	}
+
+	// map the image with its origin at the top-left to logical coordinates
+	h := float64(size.Y)
+	xform := [6]float32{
+		float32(m[0][0] * unitsPerMm),
+		float32(-m[1][0] * unitsPerMm),
+		float32(-m[0][1] * unitsPerMm),
+		float32(m[1][1] * unitsPerMm),
+		float32((m[0][1]*h + m[0][2]) * unitsPerMm),
+		float32((r.height - m[1][1]*h - m[1][2]) * unitsPerMm),
+	}
+	bounds := canvas.Rect{X: 0.0, Y: 0.0, W: float64(size.X), H: h}.Transform(m)
+
+	r.record(emrSaveDC)
+	r.record(emrSetWorldTransform, xform)
+	bmiSize := uint32(40)
+	r.record(emrStretchDIBits,
+		r.bounds(bounds.ToPath()),
+		int32(0), int32(0), // destination
+		int32(0), int32(0), int32(size.X), int32(size.Y), // source
+		uint32(80), bmiSize, uint32(80)+bmiSize, uint32(len(bits)),
+		uint32(0),                    // DIB_RGB_COLORS
+		uint32(0x00CC0020),           // SRCCOPY
+		int32(size.X), int32(size.Y), // destination size
+		uint32(40), int32(size.X), int32(size.Y), uint16(1), uint16(32), uint32(0), uint32(len(bits)), int32(0), int32(0), uint32(0), uint32(0), // BITMAPINFOHEADER with BI_RGB
+		bits,
+	)
+	r.record(emrRestoreDC, int32(-1))
+}
+
+func uint32sEqual(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, f := range a {
+		if f != b[i] {
+			return false
+		}
+	}
+	return true
+}
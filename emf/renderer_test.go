@@ -0,0 +1,90 @@
+package emf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+// readRecords returns the types and bodies of all records after checking the header.
+func readRecords(t *testing.T, b []byte) ([]uint32, [][]byte) {
+	test.That(t, 108 <= len(b), "file too small")
+	test.T(t, binary.LittleEndian.Uint32(b[40:]), uint32(0x464D4520), "signature")
+	test.T(t, int(binary.LittleEndian.Uint32(b[48:])), len(b), "size in header")
+
+	types := []uint32{}
+	bodies := [][]byte{}
+	for i := 0; i < len(b); {
+		typ, size := binary.LittleEndian.Uint32(b[i:]), binary.LittleEndian.Uint32(b[i+4:])
+		test.That(t, size%4 == 0 && 8 <= size && i+int(size) <= len(b), "bad record size", size)
+		types = append(types, typ)
+		bodies = append(bodies, b[i+8:i+int(size)])
+		i += int(size)
+	}
+	test.T(t, int(binary.LittleEndian.Uint32(b[52:])), len(types), "records in header")
+	test.T(t, types[len(types)-1], uint32(emrEOF))
+	return types, bodies
+}
+
+func TestEMF(t *testing.T) {
+	c := canvas.New(100, 50)
+	ctx := canvas.NewContext(c)
+	ctx.SetFillColor(canvas.Red)
+	ctx.DrawPath(10, 10, canvas.MustParseSVG("M0 0L10 0Q10 10 0 10z"))
+	ctx.SetStrokeColor(canvas.Blue)
+	ctx.SetStrokeWidth(0.5)
+	ctx.SetDashes(0.0, 1.0, 2.0)
+	ctx.DrawPath(10, 10, canvas.MustParseSVG("M0 0L10 0Q10 10 0 10z"))
+
+	buf := &bytes.Buffer{}
+	test.Error(t, Writer(buf, c))
+	types, bodies := readRecords(t, buf.Bytes())
+	test.T(t, types, []uint32{
+		emrHeader,
+		emrCreateBrushIndirect, emrSelectObject, emrSetPolyFillMode,
+		emrBeginPath, emrMoveToEx, emrLineTo, emrPolyBezierTo, emrCloseFigure, emrEndPath, emrFillPath,
+		emrSetMiterLimit, emrExtCreatePen, emrSelectObject,
+		emrBeginPath, emrMoveToEx, emrLineTo, emrPolyBezierTo, emrCloseFigure, emrEndPath, emrStrokeAndFillPath,
+		emrEOF,
+	})
+
+	// frame in 0.01 millimeter
+	test.T(t, bodies[0][16:32], []byte{0, 0, 0, 0, 0, 0, 0, 0, 0x10, 0x27, 0, 0, 0x88, 0x13, 0, 0})
+
+	// brush is red, move is at (10,40) from the top-left
+	test.T(t, bodies[1][8:12], []byte{255, 0, 0, 0})
+	test.T(t, bodies[5], []byte{0xe8, 0x03, 0, 0, 0xa0, 0x0f, 0, 0})
+
+	// geometric pen with flat caps, miter joins and user style dashes of 1 and 2 millimeters
+	pen := bodies[12]
+	test.T(t, binary.LittleEndian.Uint32(pen[20:]), uint32(psGeometric|psEndcapFlat|psJoinMiter|psUserStyle))
+	test.T(t, binary.LittleEndian.Uint32(pen[24:]), uint32(50))
+	test.T(t, pen[32:36], []byte{0, 0, 255, 0})
+	test.T(t, binary.LittleEndian.Uint32(pen[40:]), uint32(2))
+	test.T(t, binary.LittleEndian.Uint32(pen[44:]), uint32(100))
+	test.T(t, binary.LittleEndian.Uint32(pen[48:]), uint32(200))
+}
+
+func TestEMFImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 3))
+	img.Set(0, 0, canvas.Red)
+
+	buf := &bytes.Buffer{}
+	emf := New(buf, 100, 50)
+	emf.RenderImage(img, canvas.Identity.Translate(10, 10))
+	test.Error(t, emf.Close())
+	types, bodies := readRecords(t, buf.Bytes())
+	test.T(t, types, []uint32{emrHeader, emrSaveDC, emrSetWorldTransform, emrStretchDIBits, emrRestoreDC, emrEOF})
+
+	// bitmap info and bits follow the record at their offsets, the top-left pixel is the first pixel of the last row
+	dib := bodies[3]
+	offBmi, offBits := binary.LittleEndian.Uint32(dib[40:]), binary.LittleEndian.Uint32(dib[48:])
+	test.T(t, binary.LittleEndian.Uint32(dib[offBmi-8:]), uint32(40))
+	test.T(t, binary.LittleEndian.Uint32(dib[offBmi-8+8:]), uint32(3))
+	test.T(t, dib[offBits-8+16:offBits-8+20], []byte{0, 0, 255, 0})
+	test.T(t, dib[offBits-8:offBits-8+4], []byte{255, 255, 255, 0})
+}
@@ -0,0 +1,15 @@
+package emf
+
+import (
+	"io"
+
+	"github.com/tdewolff/canvas"
+)
+
+// Writer writes the canvas as an EMF file.
+// Be aware that EMF does not support transparency of colors.
+func Writer(w io.Writer, c *canvas.Canvas) error {
+	emf := New(w, c.W, c.H)
+	c.Render(emf)
+	return emf.Close()
+}
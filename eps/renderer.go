@@ -45,7 +45,7 @@ savematrix setmatrix
 type Renderer struct {
 	w             io.Writer
 	width, height float64
-	color         color.RGBA
+	color         color.Color
 }
 
 // New creates an encapsulated PostScript renderer.
@@ -62,10 +62,19 @@ func New(w io.Writer, width, height float64) *Renderer {
 	}
 }
 
-func (r *Renderer) setColor(color color.RGBA) {
-	if color != r.color {
-		fmt.Fprintf(r.w, " %v %v %v setrgbcolor", dec(float64(color.R)/255.0), dec(float64(color.G)/255.0), dec(float64(color.B)/255.0))
-		r.color = color
+func (r *Renderer) setColor(col color.Color) {
+	if cmyk, ok := col.(color.CMYK); ok {
+		if cmyk != r.color {
+			fmt.Fprintf(r.w, " %v %v %v %v setcmykcolor", dec(float64(cmyk.C)/255.0), dec(float64(cmyk.M)/255.0), dec(float64(cmyk.Y)/255.0), dec(float64(cmyk.K)/255.0))
+			r.color = cmyk
+		}
+		return
+	}
+
+	rgba := color.RGBAModel.Convert(col).(color.RGBA)
+	if rgba != r.color {
+		fmt.Fprintf(r.w, " %v %v %v setrgbcolor", dec(float64(rgba.R)/255.0), dec(float64(rgba.G)/255.0), dec(float64(rgba.B)/255.0))
+		r.color = rgba
 	}
 }
 
@@ -77,7 +86,11 @@ func (r *Renderer) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Ma
 	// TODO: (EPS) test ellipse, rotations etc
 	// TODO: (EPS) add drawState support
 	// TODO: (EPS) use dither to fake transparency
-	r.setColor(style.FillColor)
+	if p, ok := style.FillPaint.(canvas.ColorPaint); ok {
+		r.setColor(p.C)
+	} else {
+		r.setColor(style.FillColor)
+	}
 	r.w.Write([]byte(" "))
 	r.w.Write([]byte(path.Transform(m).ToPS()))
 	r.w.Write([]byte(" fill"))
@@ -2,9 +2,11 @@ package eps
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
 )
 
 func TestEPS(t *testing.T) {
@@ -13,3 +15,25 @@ func TestEPS(t *testing.T) {
 	eps.setColor(canvas.Red)
 	//test.String(t, string(w.Bytes()), "")
 }
+
+func TestEPSCMYK(t *testing.T) {
+	w := &bytes.Buffer{}
+	eps := New(w, 100, 80)
+	ctx := canvas.NewContext(eps)
+	ctx.SetFillColor(canvas.CMYK(0.0, 1.0, 1.0, 0.0))
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(2.0, 2.0))
+	ctx.SetFillColor(canvas.Blue)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(2.0, 2.0))
+	test.That(t, strings.HasSuffix(w.String(), " 0 1 1 0 setcmykcolor 0 0 moveto 2 0 lineto 2 2 lineto 0 2 lineto closepath fill 0 0 1 setrgbcolor 0 0 moveto 2 0 lineto 2 2 lineto 0 2 lineto closepath fill"), w.String())
+}
+
+func TestEPSCMYKText(t *testing.T) {
+	dejaVuSerif := canvas.NewFontFamily("dejavu-serif")
+	test.Error(t, dejaVuSerif.LoadFontFile("../font/DejaVuSerif.ttf", canvas.FontRegular))
+	face := dejaVuSerif.Face(12.0, canvas.CMYK(0.0, 1.0, 1.0, 0.0), canvas.FontRegular, canvas.FontNormal)
+
+	w := &bytes.Buffer{}
+	eps := New(w, 100, 80)
+	eps.RenderText(canvas.NewTextLine(face, "T", canvas.Left), canvas.Identity)
+	test.That(t, strings.Contains(w.String(), " 0 1 1 0 setcmykcolor"), "expected CMYK text color:", w.String())
+}
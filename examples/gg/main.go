@@ -0,0 +1,31 @@
+package main
+
+import (
+	"math"
+
+	"github.com/tdewolff/canvas/gg"
+)
+
+func main() {
+	const S = 400
+	dc := gg.NewContext(S, S)
+	dc.SetRGB(1.0, 1.0, 1.0)
+	dc.Clear()
+	dc.SetRGBA(0.0, 0.0, 0.0, 0.1)
+	for i := 0; i < 360; i += 15 {
+		dc.Push()
+		dc.RotateAbout(float64(i)*math.Pi/180.0, S/2, S/2)
+		dc.DrawEllipse(S/2, S/2, S*7/16, S/8)
+		dc.Fill()
+		dc.Pop()
+	}
+	if err := dc.LoadFontFace("../../font/DejaVuSerif.ttf", 32.0); err != nil {
+		panic(err)
+	}
+	dc.SetRGB(0.0, 0.0, 0.0)
+	dc.DrawStringAnchored("Hello, gg!", S/2, S/2, 0.5, 0.5)
+
+	if err := dc.SavePNG("output.png"); err != nil {
+		panic(err)
+	}
+}
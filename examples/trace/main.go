@@ -0,0 +1,39 @@
+package main
+
+import (
+	"image"
+	_ "image/png"
+	"os"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/rasterizer"
+	"github.com/tdewolff/canvas/trace"
+)
+
+func main() {
+	f, err := os.Open("../lenna.png")
+	if err != nil {
+		panic(err)
+	}
+	img, _, err := image.Decode(f)
+	f.Close()
+	if err != nil {
+		panic(err)
+	}
+
+	// reduce the number of colors and trace each color as a layer
+	paths, colors := trace.TraceColors(trace.Posterize(img, 3), nil)
+
+	dpm := 2.0
+	bounds := img.Bounds()
+	w, h := float64(bounds.Dx())/dpm, float64(bounds.Dy())/dpm
+
+	c := canvas.New(2*w+10, h)
+	ctx := canvas.NewContext(c)
+	ctx.DrawImage(0, 0, img, dpm)
+	for i, path := range paths {
+		ctx.SetFillColor(colors[i])
+		ctx.DrawPath(w+10, 0, path.Transform(canvas.Identity.Scale(1/dpm, 1/dpm)))
+	}
+	c.WriteFile("out.png", rasterizer.PNGWriter(1.0))
+}
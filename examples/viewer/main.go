@@ -0,0 +1,28 @@
+package main
+
+import (
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/viewer"
+)
+
+func main() {
+	c := canvas.New(200, 100)
+	ctx := canvas.NewContext(c)
+	draw(ctx)
+
+	// opens a window that can be panned by dragging and zoomed with the scroll wheel
+	if err := viewer.Show(c); err != nil {
+		panic(err)
+	}
+}
+
+func draw(c *canvas.Context) {
+	c.SetFillColor(canvas.Steelblue)
+	c.DrawPath(20, 20, canvas.RegularStarPolygon(7, 3, 30.0, true))
+
+	c.SetFillColor(canvas.Transparent)
+	c.SetStrokeColor(canvas.Orangered)
+	c.SetStrokeWidth(2.0)
+	c.SetDashes(0.0, 4.0, 2.0)
+	c.DrawPath(120, 50, canvas.Ellipse(50.0, 30.0))
+}
@@ -113,7 +113,7 @@ func (family *FontFamily) Use(options TypographicOptions) {
 	}
 }
 
-// Face gets the font face given by the font size (in pt).
+// Face gets the font face given by the font size (in pt). Only CMYK colors keep their model as a ColorPaint, other colors are converted to 8-bit RGBA.
 func (family *FontFamily) Face(size float64, col color.Color, style FontStyle, variant FontVariant, deco ...FontDecorator) FontFace {
 	size *= mmPerPt
 
@@ -161,6 +161,12 @@ func (family *FontFamily) Face(size float64, col color.Color, style FontStyle, v
 		}
 	}
 
+	// keep CMYK colors as a paint for renderers for print, as for Context.SetFillColor
+	var paint Paint
+	if _, ok := col.(color.CMYK); ok {
+		paint = ColorPaint{C: col}
+	}
+
 	r, g, b, a := col.RGBA()
 	return FontFace{
 		family:     family,
@@ -169,6 +175,7 @@ func (family *FontFamily) Face(size float64, col color.Color, style FontStyle, v
 		Style:      style,
 		Variant:    variant,
 		Color:      color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)},
+		Paint:      paint,
 		deco:       deco,
 		Scale:      scale,
 		Voffset:    voffset,
@@ -186,6 +193,7 @@ type FontFace struct {
 	Style   FontStyle
 	Variant FontVariant
 	Color   color.RGBA
+	Paint   Paint // ColorPaint of the original color for CMYK colors, nil otherwise
 	deco    []FontDecorator
 
 	Scale, Voffset, FauxBold, FauxItalic float64 // consequences of font style and variant
@@ -193,7 +201,7 @@ type FontFace struct {
 
 // Equals returns true when two font face are equal. In particular this allows two adjacent text spans that use the same decoration to allow the decoration to span both elements instead of two separately.
 func (ff FontFace) Equals(other FontFace) bool {
-	return ff.Font == other.Font && ff.Size == other.Size && ff.Style == other.Style && ff.Variant == other.Variant && ff.Color == other.Color && reflect.DeepEqual(ff.deco, other.deco)
+	return ff.Font == other.Font && ff.Size == other.Size && ff.Style == other.Style && ff.Variant == other.Variant && ff.Color == other.Color && paintEqual(ff.Paint, other.Paint) && reflect.DeepEqual(ff.deco, other.deco)
 }
 
 // Name returns the name of the underlying font
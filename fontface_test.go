@@ -24,6 +24,15 @@ func TestFontFamily(t *testing.T) {
 	test.Float(t, face.FauxBold, 0.48*0.583)
 	test.Float(t, face.FauxItalic, 0.3)
 	test.T(t, face.Boldness(), 1000)
+
+	cmyk := CMYK(0.0, 1.0, 1.0, 0.0)
+	face = family.Face(12.0*ptPerMm, cmyk, FontRegular, FontNormal)
+	test.T(t, face.Color, Red)
+	test.T(t, face.Paint, Paint(ColorPaint{C: cmyk}))
+	test.That(t, !face.Equals(family.Face(12.0*ptPerMm, Red, FontRegular, FontNormal)), "CMYK and RGB faces must differ")
+
+	text := NewTextLine(face, "T", Left)
+	test.T(t, text.MostCommonFontFace().Paint, Paint(ColorPaint{C: cmyk}))
 }
 
 func TestFontFace(t *testing.T) {
@@ -0,0 +1,202 @@
+package gg
+
+import (
+	"math"
+	"sort"
+
+	"github.com/tdewolff/canvas"
+)
+
+// clipEdge is a line segment of the subject or the clipping polygons.
+type clipEdge struct {
+	a, b canvas.Point
+	clip bool
+}
+
+// clipSplit is a point at which an edge is split, with t the position along the edge between 0 and 1.
+type clipSplit struct {
+	t float64
+	p canvas.Point
+}
+
+// polygon returns the vertices of the flattened path, without repeating the start point at the end.
+func polygon(p *canvas.Path) []canvas.Point {
+	coords := p.Flatten().Coords()
+	if 1 < len(coords) && coords[0].Equals(coords[len(coords)-1]) {
+		coords = coords[:len(coords)-1]
+	}
+	return coords
+}
+
+// appendEdges appends the edges of the flattened path, where each subpath is closed as it is for filling.
+func appendEdges(edges []clipEdge, p *canvas.Path, clip bool) []clipEdge {
+	for _, ps := range p.Split() {
+		poly := polygon(ps)
+		for i := range poly {
+			a, b := poly[i], poly[(i+1)%len(poly)]
+			if a != b {
+				edges = append(edges, clipEdge{a, b, clip})
+			}
+		}
+	}
+	return edges
+}
+
+// splitEdges splits the edges at their mutual intersections, including self-intersections and overlapping collinear parts, so that edges only touch at their endpoints. Both edges of an intersection are split at the exact same point, so that the resulting edges can be chained together.
+func splitEdges(edges []clipEdge) []clipEdge {
+	const eps = 1e-9
+	splits := make([][]clipSplit, len(edges))
+	// position of P on edge E, returns false if P is not on E
+	along := func(e clipEdge, p canvas.Point) (float64, bool) {
+		d := e.b.Sub(e.a)
+		t := p.Sub(e.a).Dot(d) / d.Dot(d)
+		return t, eps < t && t < 1.0-eps && math.Abs(d.PerpDot(p.Sub(e.a))) <= eps*d.Dot(d)
+	}
+	for i := range edges {
+		e := edges[i]
+		for j := i + 1; j < len(edges); j++ {
+			f := edges[j]
+			if math.Max(e.a.X, e.b.X) < math.Min(f.a.X, f.b.X) || math.Max(f.a.X, f.b.X) < math.Min(e.a.X, e.b.X) ||
+				math.Max(e.a.Y, e.b.Y) < math.Min(f.a.Y, f.b.Y) || math.Max(f.a.Y, f.b.Y) < math.Min(e.a.Y, e.b.Y) {
+				continue
+			}
+
+			// endpoints lying on the other edge, this handles touching and overlapping collinear edges
+			for _, p := range []canvas.Point{f.a, f.b} {
+				if t, ok := along(e, p); ok {
+					splits[i] = append(splits[i], clipSplit{t, p})
+				}
+			}
+			for _, p := range []canvas.Point{e.a, e.b} {
+				if t, ok := along(f, p); ok {
+					splits[j] = append(splits[j], clipSplit{t, p})
+				}
+			}
+
+			// proper crossing
+			d, g := e.b.Sub(e.a), f.b.Sub(f.a)
+			denom := d.PerpDot(g)
+			if math.Abs(denom) <= eps*d.Length()*g.Length() {
+				continue
+			}
+			s := f.a.Sub(e.a).PerpDot(g) / denom
+			t := f.a.Sub(e.a).PerpDot(d) / denom
+			if eps < s && s < 1.0-eps && eps < t && t < 1.0-eps {
+				p := e.a.Interpolate(e.b, s)
+				splits[i] = append(splits[i], clipSplit{s, p})
+				splits[j] = append(splits[j], clipSplit{t, p})
+			}
+		}
+	}
+
+	split := make([]clipEdge, 0, len(edges))
+	for i, e := range edges {
+		sort.Slice(splits[i], func(k, l int) bool { return splits[i][k].t < splits[i][l].t })
+		a := e.a
+		for _, s := range splits[i] {
+			if a != s.p {
+				split = append(split, clipEdge{a, s.p, e.clip})
+				a = s.p
+			}
+		}
+		if a != e.b {
+			split = append(split, clipEdge{a, e.b, e.clip})
+		}
+	}
+	return split
+}
+
+func insideFillRule(winding int, fillRule canvas.FillRule) bool {
+	if fillRule == canvas.EvenOdd {
+		return winding%2 != 0
+	}
+	return winding != 0
+}
+
+// clipPath returns the intersection of the path filled with the given fill rule and the clipping path filled with the non-zero fill rule. Both paths are flattened and may be concave, self-intersecting or consist of several subpaths such as holes. The result is filled with the non-zero fill rule.
+func clipPath(p *canvas.Path, fillRule canvas.FillRule, clip *canvas.Path) *canvas.Path {
+	edges := appendEdges(nil, p, false)
+	edges = appendEdges(edges, clip, true)
+	edges = splitEdges(edges)
+
+	// keep the edges that separate the inside from the outside of the intersection, oriented such that the inside is on the left
+	boundary := []clipEdge{}
+	seen := map[[2]canvas.Point]bool{}
+	for _, e := range edges {
+		// winding numbers left of the edge, by casting a ray from the midpoint to the left, and the difference with the right
+		m := e.a.Interpolate(e.b, 0.5)
+		n := e.b.Sub(e.a).Rot90CCW()
+		var windings, coincident [2]int
+		for _, f := range edges {
+			k := 0
+			if f.clip {
+				k = 1
+			}
+			if f.a == e.a && f.b == e.b {
+				coincident[k]++
+				continue
+			} else if f.a == e.b && f.b == e.a {
+				coincident[k]--
+				continue
+			}
+
+			sa, sb := n.PerpDot(f.a.Sub(m)), n.PerpDot(f.b.Sub(m))
+			if (sa <= 0.0) == (sb <= 0.0) {
+				continue
+			}
+			ta, tb := n.Dot(f.a.Sub(m)), n.Dot(f.b.Sub(m))
+			if 0.0 < ta+(tb-ta)*sa/(sa-sb) {
+				if sa <= 0.0 {
+					windings[k]++
+				} else {
+					windings[k]--
+				}
+			}
+		}
+
+		left := insideFillRule(windings[0], fillRule) && insideFillRule(windings[1], canvas.NonZero)
+		right := insideFillRule(windings[0]-coincident[0], fillRule) && insideFillRule(windings[1]-coincident[1], canvas.NonZero)
+		if left == right {
+			continue
+		} else if right {
+			e.a, e.b = e.b, e.a
+		}
+		if !seen[[2]canvas.Point{e.a, e.b}] {
+			seen[[2]canvas.Point{e.a, e.b}] = true
+			boundary = append(boundary, e)
+		}
+	}
+
+	// chain the boundary edges into closed subpaths
+	outgoing := map[canvas.Point][]int{}
+	for i, e := range boundary {
+		outgoing[e.a] = append(outgoing[e.a], i)
+	}
+	used := make([]bool, len(boundary))
+	q := &canvas.Path{}
+	for i := range boundary {
+		if used[i] {
+			continue
+		}
+		start := boundary[i].a
+		q.MoveTo(start.X, start.Y)
+		for j := i; j != -1; {
+			used[j] = true
+			end := boundary[j].b
+			if end == start {
+				break
+			}
+			q.LineTo(end.X, end.Y)
+
+			j = -1
+			for _, k := range outgoing[end] {
+				if !used[k] {
+					j = k
+					break
+				}
+			}
+		}
+		q.Close()
+	}
+	return q
+}
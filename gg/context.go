@@ -0,0 +1,829 @@
+package gg
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/pdf"
+	"github.com/tdewolff/canvas/rasterizer"
+	"github.com/tdewolff/canvas/svg"
+)
+
+const mmPerPt = 25.4 / 72.0
+const ptPerMm = 72.0 / 25.4
+
+// LineCap is the line cap used for stroke endpoints.
+type LineCap int
+
+// see LineCap
+const (
+	LineCapRound LineCap = iota
+	LineCapButt
+	LineCapSquare
+)
+
+// LineJoin is the line join used for stroke midpoints.
+type LineJoin int
+
+// see LineJoin
+const (
+	LineJoinRound LineJoin = iota
+	LineJoinBevel
+)
+
+// FillRule is the fill rule used for filling paths.
+type FillRule int
+
+// see FillRule
+const (
+	FillRuleWinding FillRule = iota
+	FillRuleEvenOdd
+)
+
+// Align is the horizontal text alignment used by DrawStringWrapped.
+type Align int
+
+// see Align
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+type state struct {
+	color       color.Color
+	fillColor   color.Color
+	strokeColor color.Color
+	dashes      []float64
+	dashOffset  float64
+	lineWidth   float64
+	lineCap     LineCap
+	lineJoin    LineJoin
+	fillRule    FillRule
+	fontFamily  *canvas.FontFamily
+	fontSize    float64
+	fontHeight  float64
+	matrix      canvas.Matrix
+	clip        *canvas.Path // in pixel coordinates and filled with the non-zero fill rule
+}
+
+// Context is a drawing context that is API-compatible with github.com/fogleman/gg, but records all drawing operations on a canvas.Canvas so that they can be written to any of the vector or raster formats. Just like for gg, all coordinates are given in pixels with the origin in the top-left corner and the Y axis pointing down. One pixel equals one point (1/72 inch) in vector outputs, and font sizes are given in points.
+type Context struct {
+	state
+	width, height int
+	c             *canvas.Canvas
+	ctx           *canvas.Context
+
+	path       *canvas.Path // in pixel coordinates, ie. the current matrix has been applied
+	start      canvas.Point
+	current    canvas.Point
+	hasCurrent bool
+	stack      []state
+}
+
+// NewContext returns a new drawing context with the width and height given in pixels.
+func NewContext(width, height int) *Context {
+	c := canvas.New(float64(width)*mmPerPt, float64(height)*mmPerPt)
+	ctx := canvas.NewContext(c)
+	ctx.SetView(canvas.Identity.Translate(0.0, c.H).Scale(mmPerPt, -mmPerPt))
+	return &Context{
+		state: state{
+			color:       color.Transparent,
+			fillColor:   color.White,
+			strokeColor: color.Black,
+			lineWidth:   1.0,
+			lineCap:     LineCapRound,
+			lineJoin:    LineJoinRound,
+			fillRule:    FillRuleWinding,
+			fontHeight:  13.0,
+			matrix:      canvas.Identity,
+		},
+		width:  width,
+		height: height,
+		c:      c,
+		ctx:    ctx,
+		path:   &canvas.Path{},
+	}
+}
+
+// NewContextForImage returns a new drawing context with the size of the given image, which is drawn as the background.
+func NewContextForImage(im image.Image) *Context {
+	size := im.Bounds().Size()
+	dc := NewContext(size.X, size.Y)
+	dc.DrawImage(im, 0, 0)
+	return dc
+}
+
+// Canvas returns the underlying canvas that records all drawing operations. It can be written to any of the supported formats.
+func (dc *Context) Canvas() *canvas.Canvas {
+	return dc.c
+}
+
+// Width returns the width of the context in pixels.
+func (dc *Context) Width() int {
+	return dc.width
+}
+
+// Height returns the height of the context in pixels.
+func (dc *Context) Height() int {
+	return dc.height
+}
+
+// Image rasterizes the canvas to an image at a resolution of one pixel per point.
+func (dc *Context) Image() image.Image {
+	return rasterizer.Draw(dc.c, canvas.DPMM(ptPerMm))
+}
+
+// SavePNG rasterizes the canvas and writes it to a PNG file.
+func (dc *Context) SavePNG(path string) error {
+	return dc.c.WriteFile(path, rasterizer.PNGWriter(canvas.DPMM(ptPerMm)))
+}
+
+// EncodePNG rasterizes the canvas and encodes it as PNG to the given writer.
+func (dc *Context) EncodePNG(w io.Writer) error {
+	return png.Encode(w, dc.Image())
+}
+
+// SaveSVG writes the canvas to an SVG file.
+func (dc *Context) SaveSVG(path string) error {
+	return dc.c.WriteFile(path, svg.Writer)
+}
+
+// SavePDF writes the canvas to a PDF file.
+func (dc *Context) SavePDF(path string) error {
+	return dc.c.WriteFile(path, pdf.Writer)
+}
+
+// GetCurrentPoint returns the current point and whether there is a current point. The point has been transformed by the current matrix.
+func (dc *Context) GetCurrentPoint() (canvas.Point, bool) {
+	return dc.current, dc.hasCurrent
+}
+
+////////////////////////////////////////////////////////////////
+
+// SetDash sets the dash pattern used for stroking, given as alternating dash and space lengths.
+func (dc *Context) SetDash(dashes ...float64) {
+	dc.dashes = dashes
+}
+
+// SetDashOffset sets the offset into the dash pattern from where to start.
+func (dc *Context) SetDashOffset(offset float64) {
+	dc.dashOffset = offset
+}
+
+// SetLineWidth sets the stroke width in pixels.
+func (dc *Context) SetLineWidth(lineWidth float64) {
+	dc.lineWidth = lineWidth
+}
+
+// SetLineCap sets the line cap used for stroke endpoints.
+func (dc *Context) SetLineCap(lineCap LineCap) {
+	dc.lineCap = lineCap
+}
+
+// SetLineCapRound sets round line caps.
+func (dc *Context) SetLineCapRound() {
+	dc.lineCap = LineCapRound
+}
+
+// SetLineCapButt sets butt line caps.
+func (dc *Context) SetLineCapButt() {
+	dc.lineCap = LineCapButt
+}
+
+// SetLineCapSquare sets square line caps.
+func (dc *Context) SetLineCapSquare() {
+	dc.lineCap = LineCapSquare
+}
+
+// SetLineJoin sets the line join used for stroke midpoints.
+func (dc *Context) SetLineJoin(lineJoin LineJoin) {
+	dc.lineJoin = lineJoin
+}
+
+// SetLineJoinRound sets round line joins.
+func (dc *Context) SetLineJoinRound() {
+	dc.lineJoin = LineJoinRound
+}
+
+// SetLineJoinBevel sets bevel line joins.
+func (dc *Context) SetLineJoinBevel() {
+	dc.lineJoin = LineJoinBevel
+}
+
+// SetFillRule sets the fill rule used for filling and clipping.
+func (dc *Context) SetFillRule(fillRule FillRule) {
+	dc.fillRule = fillRule
+}
+
+// SetFillRuleWinding sets the non-zero winding fill rule.
+func (dc *Context) SetFillRuleWinding() {
+	dc.fillRule = FillRuleWinding
+}
+
+// SetFillRuleEvenOdd sets the even-odd fill rule.
+func (dc *Context) SetFillRuleEvenOdd() {
+	dc.fillRule = FillRuleEvenOdd
+}
+
+// SetColor sets the color used for filling, stroking and drawing text.
+func (dc *Context) SetColor(c color.Color) {
+	dc.color = c
+	dc.fillColor = c
+	dc.strokeColor = c
+}
+
+// SetHexColor sets the color from a hexadecimal string such as #RGB, #RRGGBB or #RRGGBBAA.
+func (dc *Context) SetHexColor(x string) {
+	r, g, b, a := parseHexColor(x)
+	dc.SetRGBA255(r, g, b, a)
+}
+
+// SetRGBA255 sets the color with its components in the range [0,255].
+func (dc *Context) SetRGBA255(r, g, b, a int) {
+	dc.SetColor(color.NRGBA{uint8(r), uint8(g), uint8(b), uint8(a)})
+}
+
+// SetRGB255 sets an opaque color with its components in the range [0,255].
+func (dc *Context) SetRGB255(r, g, b int) {
+	dc.SetRGBA255(r, g, b, 255)
+}
+
+// SetRGBA sets the color with its components in the range [0,1].
+func (dc *Context) SetRGBA(r, g, b, a float64) {
+	dc.SetColor(color.NRGBA{uint8(r * 255.0), uint8(g * 255.0), uint8(b * 255.0), uint8(a * 255.0)})
+}
+
+// SetRGB sets an opaque color with its components in the range [0,1].
+func (dc *Context) SetRGB(r, g, b float64) {
+	dc.SetRGBA(r, g, b, 1.0)
+}
+
+////////////////////////////////////////////////////////////////
+
+// MoveTo starts a new subpath at (x,y).
+func (dc *Context) MoveTo(x, y float64) {
+	p := dc.matrix.Dot(canvas.Point{X: x, Y: y})
+	dc.path.MoveTo(p.X, p.Y)
+	dc.start = p
+	dc.current = p
+	dc.hasCurrent = true
+}
+
+// LineTo adds a line segment to (x,y). If there is no current point, it is equivalent to MoveTo.
+func (dc *Context) LineTo(x, y float64) {
+	if !dc.hasCurrent {
+		dc.MoveTo(x, y)
+		return
+	}
+	p := dc.matrix.Dot(canvas.Point{X: x, Y: y})
+	dc.path.LineTo(p.X, p.Y)
+	dc.current = p
+}
+
+// QuadraticTo adds a quadratic Bézier segment with control point (x1,y1) and end point (x2,y2). If there is no current point, it first moves to (x1,y1).
+func (dc *Context) QuadraticTo(x1, y1, x2, y2 float64) {
+	if !dc.hasCurrent {
+		dc.MoveTo(x1, y1)
+	}
+	cp := dc.matrix.Dot(canvas.Point{X: x1, Y: y1})
+	p := dc.matrix.Dot(canvas.Point{X: x2, Y: y2})
+	dc.path.QuadTo(cp.X, cp.Y, p.X, p.Y)
+	dc.current = p
+}
+
+// CubicTo adds a cubic Bézier segment with control points (x1,y1) and (x2,y2) and end point (x3,y3). If there is no current point, it first moves to (x1,y1).
+func (dc *Context) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	if !dc.hasCurrent {
+		dc.MoveTo(x1, y1)
+	}
+	cp1 := dc.matrix.Dot(canvas.Point{X: x1, Y: y1})
+	cp2 := dc.matrix.Dot(canvas.Point{X: x2, Y: y2})
+	p := dc.matrix.Dot(canvas.Point{X: x3, Y: y3})
+	dc.path.CubeTo(cp1.X, cp1.Y, cp2.X, cp2.Y, p.X, p.Y)
+	dc.current = p
+}
+
+// ClosePath closes the current subpath.
+func (dc *Context) ClosePath() {
+	if dc.hasCurrent {
+		dc.path.Close()
+		dc.current = dc.start
+	}
+}
+
+// ClearPath removes all subpaths from the current path.
+func (dc *Context) ClearPath() {
+	dc.path = &canvas.Path{}
+	dc.hasCurrent = false
+}
+
+// NewSubPath starts a new subpath, so that the next LineTo will not be connected to the current point.
+func (dc *Context) NewSubPath() {
+	dc.hasCurrent = false
+}
+
+// appendPath appends a path given in user coordinates to the current path, connecting it to the current point if there is one.
+func (dc *Context) appendPath(p *canvas.Path) {
+	if p.Empty() {
+		return
+	}
+	p = p.Transform(dc.matrix)
+	start := p.Coords()[0]
+	if dc.hasCurrent {
+		dc.path.LineTo(start.X, start.Y)
+	} else {
+		dc.path.MoveTo(start.X, start.Y)
+		dc.start = start
+	}
+	dc.path = dc.path.Join(p)
+	dc.current = dc.path.Pos()
+	dc.hasCurrent = true
+}
+
+////////////////////////////////////////////////////////////////
+
+// style returns the path style for drawing paths given in pixel coordinates. The canvas does not scale the stroke width and dashes by the view, so they are converted from pixels to millimeters.
+func (dc *Context) style() canvas.Style {
+	style := canvas.DefaultStyle
+	style.FillColor = toRGBA(dc.fillColor)
+	style.StrokeColor = toRGBA(dc.strokeColor)
+	style.StrokeWidth = dc.lineWidth * mmPerPt
+	switch dc.lineCap {
+	case LineCapButt:
+		style.StrokeCapper = canvas.ButtCap
+	case LineCapSquare:
+		style.StrokeCapper = canvas.SquareCap
+	default:
+		style.StrokeCapper = canvas.RoundCap
+	}
+	switch dc.lineJoin {
+	case LineJoinBevel:
+		style.StrokeJoiner = canvas.BevelJoin
+	default:
+		style.StrokeJoiner = canvas.RoundJoin
+	}
+	style.DashOffset = dc.dashOffset * mmPerPt
+	style.Dashes = make([]float64, len(dc.dashes))
+	for i, dash := range dc.dashes {
+		style.Dashes[i] = dash * mmPerPt
+	}
+	if dc.fillRule == FillRuleEvenOdd {
+		style.FillRule = canvas.EvenOdd
+	} else {
+		style.FillRule = canvas.NonZero
+	}
+	return style
+}
+
+func (dc *Context) draw(path *canvas.Path, style canvas.Style) {
+	dc.ctx.Style = style
+	dc.ctx.DrawPath(0.0, 0.0, path)
+}
+
+// StrokePreserve strokes the current path without clearing it.
+func (dc *Context) StrokePreserve() {
+	style := dc.style()
+	style.FillColor = canvas.Transparent
+	if dc.clip == nil {
+		dc.draw(dc.path, style)
+		return
+	}
+
+	// stroke explicitly in pixel coordinates so that the outline can be clipped
+	path := dc.path
+	if 0 < len(dc.dashes) {
+		path = path.Dash(dc.dashOffset, dc.dashes...)
+	}
+	path = path.Stroke(dc.lineWidth, style.StrokeCapper, style.StrokeJoiner)
+	style.FillColor = style.StrokeColor
+	style.StrokeColor = canvas.Transparent
+	style.FillRule = canvas.NonZero
+	dc.draw(clipPath(path, canvas.NonZero, dc.clip), style)
+}
+
+// Stroke strokes the current path and clears it.
+func (dc *Context) Stroke() {
+	dc.StrokePreserve()
+	dc.ClearPath()
+}
+
+// FillPreserve fills the current path without clearing it.
+func (dc *Context) FillPreserve() {
+	style := dc.style()
+	style.StrokeColor = canvas.Transparent
+	path := dc.path
+	if dc.clip != nil {
+		path = clipPath(path, style.FillRule, dc.clip)
+		style.FillRule = canvas.NonZero
+	}
+	dc.draw(path, style)
+}
+
+// Fill fills the current path and clears it.
+func (dc *Context) Fill() {
+	dc.FillPreserve()
+	dc.ClearPath()
+}
+
+// ClipPreserve intersects the clipping region with the current path, filled with the current fill rule, without clearing it. Subsequent fills, strokes, text and images are clipped. Clipping is done on the paths themselves so that the output remains vectorized, curves are flattened in the process.
+func (dc *Context) ClipPreserve() {
+	clip := dc.clip
+	if clip == nil {
+		clip = canvas.Rectangle(float64(dc.width), float64(dc.height))
+	}
+	dc.clip = clipPath(dc.path, dc.style().FillRule, clip)
+}
+
+// Clip intersects the clipping region with the current path and clears it.
+func (dc *Context) Clip() {
+	dc.ClipPreserve()
+	dc.ClearPath()
+}
+
+// ResetClip removes the clipping region.
+func (dc *Context) ResetClip() {
+	dc.clip = nil
+}
+
+// Clear removes everything that has been drawn and fills the entire context with the current color.
+func (dc *Context) Clear() {
+	dc.c.Reset()
+	style := canvas.DefaultStyle
+	style.FillColor = toRGBA(dc.color)
+	dc.draw(canvas.Rectangle(float64(dc.width), float64(dc.height)), style)
+}
+
+// SetPixel fills the pixel at (x,y) with the current color.
+func (dc *Context) SetPixel(x, y int) {
+	style := canvas.DefaultStyle
+	style.FillColor = toRGBA(dc.color)
+	dc.draw(canvas.Rectangle(1.0, 1.0).Translate(float64(x), float64(y)), style)
+}
+
+// DrawPoint adds a circle of radius r in pixels around (x,y), which is not scaled by the current matrix.
+func (dc *Context) DrawPoint(x, y, r float64) {
+	p := dc.matrix.Dot(canvas.Point{X: x, Y: y})
+	matrix := dc.matrix
+	dc.matrix = canvas.Identity
+	dc.DrawCircle(p.X, p.Y, r)
+	dc.matrix = matrix
+}
+
+// DrawLine adds a line segment from (x1,y1) to (x2,y2).
+func (dc *Context) DrawLine(x1, y1, x2, y2 float64) {
+	dc.MoveTo(x1, y1)
+	dc.LineTo(x2, y2)
+}
+
+// DrawRectangle adds a rectangle with top-left corner (x,y).
+func (dc *Context) DrawRectangle(x, y, w, h float64) {
+	dc.NewSubPath()
+	dc.MoveTo(x, y)
+	dc.LineTo(x+w, y)
+	dc.LineTo(x+w, y+h)
+	dc.LineTo(x, y+h)
+	dc.ClosePath()
+}
+
+// DrawRoundedRectangle adds a rectangle with top-left corner (x,y) and rounded corners of radius r.
+func (dc *Context) DrawRoundedRectangle(x, y, w, h, r float64) {
+	dc.NewSubPath()
+	dc.appendPath(canvas.RoundedRectangle(w, h, r).Translate(x, y))
+	dc.ClosePath()
+}
+
+// DrawEllipticalArc adds an elliptical arc centered at (x,y) with radii rx and ry, from angle1 to angle2 in radians. If there is a current point, a line is added to the start of the arc.
+func (dc *Context) DrawEllipticalArc(x, y, rx, ry, angle1, angle2 float64) {
+	p := &canvas.Path{}
+	p.MoveTo(x+rx*math.Cos(angle1), y+ry*math.Sin(angle1))
+	p.Arc(rx, ry, 0.0, angle1*180.0/math.Pi, angle2*180.0/math.Pi)
+	dc.appendPath(p)
+}
+
+// DrawEllipse adds an ellipse centered at (x,y) with radii rx and ry.
+func (dc *Context) DrawEllipse(x, y, rx, ry float64) {
+	dc.NewSubPath()
+	dc.DrawEllipticalArc(x, y, rx, ry, 0.0, 2.0*math.Pi)
+	dc.ClosePath()
+}
+
+// DrawArc adds a circular arc centered at (x,y) with radius r, from angle1 to angle2 in radians.
+func (dc *Context) DrawArc(x, y, r, angle1, angle2 float64) {
+	dc.DrawEllipticalArc(x, y, r, r, angle1, angle2)
+}
+
+// DrawCircle adds a circle centered at (x,y) with radius r.
+func (dc *Context) DrawCircle(x, y, r float64) {
+	dc.NewSubPath()
+	dc.DrawEllipticalArc(x, y, r, r, 0.0, 2.0*math.Pi)
+	dc.ClosePath()
+}
+
+// DrawRegularPolygon adds a regular polygon of n sides centered at (x,y) with circumradius r, rotated by rotation in radians.
+func (dc *Context) DrawRegularPolygon(n int, x, y, r, rotation float64) {
+	angle := 2.0 * math.Pi / float64(n)
+	rotation -= math.Pi / 2.0
+	if n%2 == 0 {
+		rotation += angle / 2.0
+	}
+	dc.NewSubPath()
+	for i := 0; i < n; i++ {
+		a := rotation + angle*float64(i)
+		dc.LineTo(x+r*math.Cos(a), y+r*math.Sin(a))
+	}
+	dc.ClosePath()
+}
+
+// DrawImage draws the image with its top-left corner at (x,y).
+func (dc *Context) DrawImage(im image.Image, x, y int) {
+	dc.DrawImageAnchored(im, x, y, 0.0, 0.0)
+}
+
+// DrawImageAnchored draws the image at the anchor point (x - w*ax, y - h*ay), where w and h are the size of the image. Use ax=0.5 and ay=0.5 to center the image at (x,y).
+func (dc *Context) DrawImageAnchored(im image.Image, x, y int, ax, ay float64) {
+	size := im.Bounds().Size()
+	fx := float64(x) - ax*float64(size.X)
+	fy := float64(y) - ay*float64(size.Y)
+
+	m := dc.matrix.Translate(fx, fy+float64(size.Y)).ReflectY()
+	if dc.clip == nil {
+		dc.ctx.Push()
+		dc.ctx.ComposeView(m)
+		dc.ctx.DrawImage(0.0, 0.0, im, 1.0)
+		dc.ctx.Pop()
+		return
+	}
+
+	// fill the clipped bounds of the image with the image as paint
+	paint := canvas.NewImagePaint(im, 1.0)
+	paint.M = m
+	style := canvas.DefaultStyle
+	style.FillColor = paint.Color()
+	style.FillPaint = paint
+	dc.draw(clipPath(canvas.Rectangle(float64(size.X), float64(size.Y)).Transform(m), canvas.NonZero, dc.clip), style)
+}
+
+////////////////////////////////////////////////////////////////
+
+// SetFontFace sets the font family and font size in points used for drawing text.
+func (dc *Context) SetFontFace(family *canvas.FontFamily, points float64) {
+	dc.fontFamily = family
+	dc.fontSize = points
+	dc.fontHeight = points * 72.0 / 96.0
+}
+
+// LoadFontFace loads a TTF, OTF, WOFF or WOFF2 font file and uses it for drawing text at the font size given in points.
+func (dc *Context) LoadFontFace(path string, points float64) error {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	family := canvas.NewFontFamily(name)
+	if err := family.LoadFontFile(path, canvas.FontRegular); err != nil {
+		return err
+	}
+	dc.SetFontFace(family, points)
+	return nil
+}
+
+// FontHeight returns the font height in pixels.
+func (dc *Context) FontHeight() float64 {
+	return dc.fontHeight
+}
+
+func (dc *Context) face() canvas.FontFace {
+	if dc.fontFamily == nil {
+		panic("gg: no font face loaded")
+	}
+	return dc.fontFamily.Face(dc.fontSize, dc.color, canvas.FontRegular, canvas.FontNormal)
+}
+
+// DrawString draws the text with its baseline starting at (x,y).
+func (dc *Context) DrawString(s string, x, y float64) {
+	dc.DrawStringAnchored(s, x, y, 0.0, 0.0)
+}
+
+// DrawStringAnchored draws the text at the anchor point (x - w*ax, y + h*ay), where w and h are the size of the text. Use ax=0.5 and ay=0.5 to center the text at (x,y).
+func (dc *Context) DrawStringAnchored(s string, x, y, ax, ay float64) {
+	w, h := dc.MeasureString(s)
+	x -= ax * w
+	y += ay * h
+
+	// text is laid out in millimeters with the Y axis pointing up
+	m := dc.matrix.Translate(x, y).Scale(ptPerMm, -ptPerMm)
+	text := canvas.NewTextLine(dc.face(), s, canvas.Left)
+	if dc.clip == nil {
+		dc.ctx.Push()
+		dc.ctx.ComposeView(m)
+		dc.ctx.DrawText(0.0, 0.0, text)
+		dc.ctx.Pop()
+		return
+	}
+
+	paths, colors := text.ToPaths()
+	for i, path := range paths {
+		style := canvas.DefaultStyle
+		style.FillColor = colors[i]
+		dc.draw(clipPath(path.Transform(m), canvas.NonZero, dc.clip), style)
+	}
+}
+
+// DrawStringWrapped word-wraps the text to the given width and draws it at the anchor point (x - width*ax, y - h*ay), where h is the height of the wrapped text. Lines are separated by lineSpacing times the font height and aligned by align.
+func (dc *Context) DrawStringWrapped(s string, x, y, ax, ay, width, lineSpacing float64, align Align) {
+	lines := dc.WordWrap(s, width)
+
+	h := float64(len(lines)) * dc.fontHeight * lineSpacing
+	h -= (lineSpacing - 1.0) * dc.fontHeight
+
+	x -= ax * width
+	y -= ay * h
+	switch align {
+	case AlignLeft:
+		ax = 0.0
+	case AlignCenter:
+		ax = 0.5
+		x += width / 2.0
+	case AlignRight:
+		ax = 1.0
+		x += width
+	}
+	ay = 1.0
+	for _, line := range lines {
+		dc.DrawStringAnchored(line, x, y, ax, ay)
+		y += dc.fontHeight * lineSpacing
+	}
+}
+
+// MeasureMultilineString returns the width and height in pixels of text that may contain newlines, using the given line spacing.
+func (dc *Context) MeasureMultilineString(s string, lineSpacing float64) (float64, float64) {
+	lines := strings.Split(s, "\n")
+	height := float64(len(lines)) * dc.fontHeight * lineSpacing
+	height -= (lineSpacing - 1.0) * dc.fontHeight
+
+	width := 0.0
+	for _, line := range lines {
+		if w, _ := dc.MeasureString(line); width < w {
+			width = w
+		}
+	}
+	return width, height
+}
+
+// MeasureString returns the width and height in pixels of the text.
+func (dc *Context) MeasureString(s string) (float64, float64) {
+	return dc.face().TextWidth(s) * ptPerMm, dc.fontHeight
+}
+
+// WordWrap splits the text into lines that fit the given width in pixels.
+func (dc *Context) WordWrap(s string, w float64) []string {
+	return wordWrap(dc, s, w)
+}
+
+////////////////////////////////////////////////////////////////
+
+// Identity resets the current matrix.
+func (dc *Context) Identity() {
+	dc.matrix = canvas.Identity
+}
+
+// Translate translates the current matrix.
+func (dc *Context) Translate(x, y float64) {
+	dc.matrix = dc.matrix.Translate(x, y)
+}
+
+// Scale scales the current matrix.
+func (dc *Context) Scale(x, y float64) {
+	dc.matrix = dc.matrix.Scale(x, y)
+}
+
+// ScaleAbout scales the current matrix around (x,y).
+func (dc *Context) ScaleAbout(sx, sy, x, y float64) {
+	dc.matrix = dc.matrix.ScaleAbout(sx, sy, x, y)
+}
+
+// Rotate rotates the current matrix by angle in radians.
+func (dc *Context) Rotate(angle float64) {
+	dc.matrix = dc.matrix.Rotate(angle * 180.0 / math.Pi)
+}
+
+// RotateAbout rotates the current matrix by angle in radians around (x,y).
+func (dc *Context) RotateAbout(angle, x, y float64) {
+	dc.matrix = dc.matrix.RotateAbout(angle*180.0/math.Pi, x, y)
+}
+
+// Shear shears the current matrix.
+func (dc *Context) Shear(x, y float64) {
+	dc.matrix = dc.matrix.Shear(x, y)
+}
+
+// ShearAbout shears the current matrix around (x,y).
+func (dc *Context) ShearAbout(sx, sy, x, y float64) {
+	dc.matrix = dc.matrix.ShearAbout(sx, sy, x, y)
+}
+
+// TransformPoint transforms (x,y) by the current matrix.
+func (dc *Context) TransformPoint(x, y float64) (float64, float64) {
+	p := dc.matrix.Dot(canvas.Point{X: x, Y: y})
+	return p.X, p.Y
+}
+
+// InvertY flips the Y axis so that it points up, with the origin in the bottom-left corner.
+func (dc *Context) InvertY() {
+	dc.Translate(0.0, float64(dc.height))
+	dc.Scale(1.0, -1.0)
+}
+
+// Push saves the current state (colors, stroke settings, font, matrix and clipping region) on a stack.
+func (dc *Context) Push() {
+	s := dc.state
+	s.dashes = append([]float64{}, dc.dashes...)
+	dc.stack = append(dc.stack, s)
+}
+
+// Pop restores the last pushed state. If there are no states on the stack, this will do nothing.
+func (dc *Context) Pop() {
+	if len(dc.stack) == 0 {
+		return
+	}
+	dc.state = dc.stack[len(dc.stack)-1]
+	dc.stack = dc.stack[:len(dc.stack)-1]
+}
+
+////////////////////////////////////////////////////////////////
+
+func toRGBA(col color.Color) color.RGBA {
+	r, g, b, a := col.RGBA()
+	return color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+}
+
+func parseHexColor(x string) (int, int, int, int) {
+	x = strings.TrimPrefix(x, "#")
+	if len(x) == 3 || len(x) == 4 {
+		s := ""
+		for _, c := range x {
+			s += string(c) + string(c)
+		}
+		x = s
+	}
+
+	a := 255
+	v, _ := strconv.ParseUint(x, 16, 32)
+	if len(x) == 8 {
+		a = int(v & 0xff)
+		v >>= 8
+	} else if len(x) != 6 {
+		return 0, 0, 0, 255
+	}
+	return int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff), a
+}
+
+func splitOnSpace(x string) []string {
+	var result []string
+	pi := 0
+	ps := false
+	for i, c := range x {
+		s := unicode.IsSpace(c)
+		if s != ps && 0 < i {
+			result = append(result, x[pi:i])
+			pi = i
+		}
+		ps = s
+	}
+	result = append(result, x[pi:])
+	return result
+}
+
+func wordWrap(dc *Context, s string, width float64) []string {
+	var result []string
+	for _, line := range strings.Split(s, "\n") {
+		fields := splitOnSpace(line)
+		if len(fields)%2 == 1 {
+			fields = append(fields, "")
+		}
+		x := ""
+		for i := 0; i < len(fields); i += 2 {
+			if w, _ := dc.MeasureString(x + fields[i]); width < w {
+				if x == "" {
+					result = append(result, fields[i])
+					continue
+				}
+				result = append(result, x)
+				x = ""
+			}
+			x += fields[i] + fields[i+1]
+		}
+		if x != "" {
+			result = append(result, x)
+		}
+	}
+	for i, line := range result {
+		result[i] = strings.TrimSpace(line)
+	}
+	return result
+}
@@ -0,0 +1,249 @@
+package gg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func TestContextPath(t *testing.T) {
+	dc := NewContext(100, 100)
+	dc.Translate(10.0, 20.0)
+	dc.MoveTo(0.0, 0.0)
+	dc.LineTo(10.0, 0.0)
+
+	p, ok := dc.GetCurrentPoint()
+	test.That(t, ok)
+	test.T(t, p, canvas.Point{X: 20.0, Y: 20.0})
+
+	dc.ClosePath()
+	p, _ = dc.GetCurrentPoint()
+	test.T(t, p, canvas.Point{X: 10.0, Y: 20.0})
+
+	dc.ClearPath()
+	_, ok = dc.GetCurrentPoint()
+	test.That(t, !ok)
+}
+
+func TestContextTransform(t *testing.T) {
+	dc := NewContext(100, 100)
+	dc.RotateAbout(math.Pi/2.0, 50.0, 50.0)
+	x, y := dc.TransformPoint(60.0, 50.0)
+	test.Float(t, x, 50.0)
+	test.Float(t, y, 60.0)
+
+	dc.Push()
+	dc.Identity()
+	x, y = dc.TransformPoint(60.0, 50.0)
+	test.Float(t, x, 60.0)
+	test.Float(t, y, 50.0)
+	dc.Pop()
+
+	x, y = dc.TransformPoint(60.0, 50.0)
+	test.Float(t, x, 50.0)
+	test.Float(t, y, 60.0)
+}
+
+func TestContextFill(t *testing.T) {
+	dc := NewContext(10, 10)
+	dc.SetRGB(1.0, 0.0, 0.0)
+	dc.DrawRectangle(0.0, 0.0, 5.0, 10.0)
+	dc.Fill()
+
+	img := dc.Image()
+	test.T(t, img.Bounds().Size().X, 10)
+	test.T(t, img.At(2, 5), color.RGBA{255, 0, 0, 255})
+	test.T(t, img.At(8, 5), color.RGBA{0, 0, 0, 0})
+}
+
+func TestContextClip(t *testing.T) {
+	dc := NewContext(10, 10)
+	dc.DrawRectangle(0.0, 0.0, 5.0, 5.0)
+	dc.Clip()
+	dc.SetRGB(0.0, 0.0, 1.0)
+	dc.DrawCircle(5.0, 5.0, 10.0)
+	dc.Fill()
+
+	img := dc.Image()
+	test.T(t, img.At(2, 2), color.RGBA{0, 0, 255, 255})
+	test.T(t, img.At(7, 7), color.RGBA{0, 0, 0, 0})
+}
+
+func TestContextClipConcave(t *testing.T) {
+	blue := color.RGBA{0, 0, 255, 255}
+	transparent := color.RGBA{0, 0, 0, 0}
+
+	// L-shape
+	dc := NewContext(10, 10)
+	dc.MoveTo(0.0, 0.0)
+	dc.LineTo(4.0, 0.0)
+	dc.LineTo(4.0, 6.0)
+	dc.LineTo(10.0, 6.0)
+	dc.LineTo(10.0, 10.0)
+	dc.LineTo(0.0, 10.0)
+	dc.ClosePath()
+	dc.Clip()
+	dc.SetRGB(0.0, 0.0, 1.0)
+	dc.DrawRectangle(0.0, 0.0, 10.0, 10.0)
+	dc.Fill()
+
+	img := dc.Image()
+	test.T(t, img.At(2, 2), blue)
+	test.T(t, img.At(8, 8), blue)
+	test.T(t, img.At(7, 2), transparent)
+
+	// two separate subpaths
+	dc = NewContext(10, 10)
+	dc.DrawRectangle(0.0, 0.0, 4.0, 4.0)
+	dc.DrawRectangle(6.0, 6.0, 4.0, 4.0)
+	dc.Clip()
+	dc.SetRGB(0.0, 0.0, 1.0)
+	dc.DrawRectangle(0.0, 0.0, 10.0, 10.0)
+	dc.Fill()
+
+	img = dc.Image()
+	test.T(t, img.At(2, 2), blue)
+	test.T(t, img.At(8, 8), blue)
+	test.T(t, img.At(5, 5), transparent)
+	test.T(t, img.At(8, 2), transparent)
+
+	// hole using the even-odd fill rule
+	dc = NewContext(10, 10)
+	dc.DrawRectangle(0.0, 0.0, 10.0, 10.0)
+	dc.DrawRectangle(3.0, 3.0, 4.0, 4.0)
+	dc.SetFillRuleEvenOdd()
+	dc.Clip()
+	dc.SetFillRuleWinding()
+	dc.SetRGB(0.0, 0.0, 1.0)
+	dc.DrawCircle(5.0, 5.0, 10.0)
+	dc.Fill()
+
+	img = dc.Image()
+	test.T(t, img.At(1, 1), blue)
+	test.T(t, img.At(5, 5), transparent)
+
+	// nested clipping regions intersect, the stroke is clipped as well
+	dc = NewContext(10, 10)
+	dc.DrawRectangle(0.0, 0.0, 6.0, 10.0)
+	dc.Clip()
+	dc.DrawRectangle(4.0, 0.0, 6.0, 10.0)
+	dc.Clip()
+	dc.SetRGB(0.0, 0.0, 1.0)
+	dc.SetLineWidth(2.0)
+	dc.DrawLine(0.0, 5.0, 10.0, 5.0)
+	dc.Stroke()
+
+	img = dc.Image()
+	test.T(t, img.At(5, 5), blue)
+	test.T(t, img.At(2, 5), transparent)
+	test.T(t, img.At(8, 5), transparent)
+}
+
+func TestClipPath(t *testing.T) {
+	area := func(p *canvas.Path) float64 {
+		a := 0.0
+		for _, ps := range p.Split() {
+			poly := polygon(ps)
+			for i := range poly {
+				a += poly[i].PerpDot(poly[(i+1)%len(poly)]) / 2.0
+			}
+		}
+		return a
+	}
+
+	square := canvas.Rectangle(10.0, 10.0)
+	star := canvas.StarPolygon(5, 5.0, 2.0, true).Translate(5.0, 5.0)
+	test.Float(t, area(clipPath(star, canvas.NonZero, square)), area(star))
+	test.Float(t, area(clipPath(square, canvas.NonZero, star)), area(star))
+
+	// self-intersecting pentagram, where the center is inside for the non-zero fill rule and outside for the even-odd fill rule
+	pentagram := &canvas.Path{}
+	for i := 0; i < 5; i++ {
+		phi := math.Pi/2.0 + float64(2*i)*2.0*math.Pi/5.0
+		if i == 0 {
+			pentagram.MoveTo(5.0+4.0*math.Cos(phi), 5.0+4.0*math.Sin(phi))
+		} else {
+			pentagram.LineTo(5.0+4.0*math.Cos(phi), 5.0+4.0*math.Sin(phi))
+		}
+	}
+	pentagram.Close()
+	center := canvas.Rectangle(1.0, 1.0).Translate(4.5, 4.5)
+	test.Float(t, area(clipPath(center, canvas.NonZero, pentagram)), 1.0)
+	test.Float(t, area(clipPath(pentagram, canvas.EvenOdd, center)), 0.0)
+	test.Float(t, area(clipPath(pentagram, canvas.NonZero, center)), 1.0)
+
+	// coincident edges
+	test.Float(t, area(clipPath(square, canvas.NonZero, square)), 100.0)
+	half := canvas.Rectangle(5.0, 10.0)
+	test.Float(t, area(clipPath(square, canvas.NonZero, half)), 50.0)
+}
+
+func TestParseHexColor(t *testing.T) {
+	var tts = []struct {
+		hex        string
+		r, g, b, a int
+	}{
+		{"#f00", 255, 0, 0, 255},
+		{"00ff0080", 0, 255, 0, 128},
+		{"#123456", 0x12, 0x34, 0x56, 255},
+	}
+	for _, tt := range tts {
+		t.Run(tt.hex, func(t *testing.T) {
+			r, g, b, a := parseHexColor(tt.hex)
+			test.T(t, []int{r, g, b, a}, []int{tt.r, tt.g, tt.b, tt.a})
+		})
+	}
+}
+
+func TestContextStrokeWidth(t *testing.T) {
+	// count the covered pixels of a vertical line of the given width in pixels
+	width := func(dc *Context) int {
+		img := dc.Image()
+		n := 0
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			if _, _, _, a := img.At(x, 10).RGBA(); a == 0xffff {
+				n++
+			}
+		}
+		return n
+	}
+
+	for _, lineWidth := range []float64{2.0, 4.0} {
+		dc := NewContext(20, 20)
+		dc.SetLineWidth(lineWidth)
+		dc.SetLineCapButt()
+		dc.DrawLine(10.0, 0.0, 10.0, 20.0)
+		dc.Stroke()
+		test.T(t, width(dc), int(lineWidth))
+
+		// clipped strokes have the same width
+		dc = NewContext(20, 20)
+		dc.DrawRectangle(0.0, 0.0, 20.0, 20.0)
+		dc.Clip()
+		dc.SetLineWidth(lineWidth)
+		dc.SetLineCapButt()
+		dc.DrawLine(10.0, 0.0, 10.0, 20.0)
+		dc.Stroke()
+		test.T(t, width(dc), int(lineWidth))
+	}
+}
+
+func TestContextClipImage(t *testing.T) {
+	im := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(im, im.Bounds(), image.NewUniform(color.RGBA{255, 0, 0, 255}), image.Point{}, draw.Src)
+
+	dc := NewContext(10, 10)
+	dc.DrawRectangle(0.0, 0.0, 5.0, 5.0)
+	dc.Clip()
+	dc.DrawImage(im, 0, 0)
+
+	img := dc.Image()
+	test.T(t, img.At(2, 2), color.RGBA{255, 0, 0, 255})
+	test.T(t, img.At(7, 7), color.RGBA{0, 0, 0, 0})
+	test.T(t, img.At(2, 7), color.RGBA{0, 0, 0, 0})
+}
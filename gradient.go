@@ -0,0 +1,223 @@
+package canvas
+
+import (
+	"image/color"
+	"math"
+	"sort"
+)
+
+// Spread is the method by which a gradient is continued before its start and after its end.
+type Spread int
+
+// see Spread
+const (
+	PadSpread     Spread = iota // use the colors of the first and last stop
+	ReflectSpread               // repeat the gradient, reversing every other repetition
+	RepeatSpread                // repeat the gradient
+)
+
+func (spread Spread) String() string {
+	switch spread {
+	case ReflectSpread:
+		return "Reflect"
+	case RepeatSpread:
+		return "Repeat"
+	}
+	return "Pad"
+}
+
+// Stop is a color stop of a gradient at an offset between 0 and 1.
+type Stop struct {
+	Offset float64
+	Color  color.RGBA
+	Paint  Paint // ColorPaint of the original color for CMYK colors, nil otherwise
+}
+
+// Gradient holds the color stops, spread method and transformation that are common to the gradient paints. The transformation matrix maps the gradient's coordinates to path coordinates. Colors are interpolated between stops without premultiplied alpha, as in SVG and PDF.
+type Gradient struct {
+	Stops  []Stop
+	Spread Spread
+	M      Matrix
+}
+
+// AddColorStop adds a color stop at an offset between 0 and 1. Stops are kept sorted by their offset, and stops at the same offset keep the order in which they were added so that they form a sharp transition. Only CMYK colors keep their model as a ColorPaint for renderers that support them, other colors are converted to 8-bit RGBA.
+func (g *Gradient) AddColorStop(offset float64, col color.Color) {
+	stop := Stop{Offset: math.Max(0.0, math.Min(1.0, offset)), Color: toRGBA(col)}
+	if _, ok := col.(color.CMYK); ok {
+		stop.Paint = ColorPaint{C: col}
+	}
+	i := sort.Search(len(g.Stops), func(i int) bool { return stop.Offset < g.Stops[i].Offset })
+	g.Stops = append(g.Stops, Stop{})
+	copy(g.Stops[i+1:], g.Stops[i:])
+	g.Stops[i] = stop
+}
+
+// ColorAt returns the color at offset t along the gradient, where the gradient runs from 0 to 1 and is continued using its spread method.
+func (g *Gradient) ColorAt(t float64) color.RGBA {
+	if len(g.Stops) == 0 {
+		return Transparent
+	}
+
+	switch g.Spread {
+	case RepeatSpread:
+		t -= math.Floor(t)
+	case ReflectSpread:
+		t = math.Abs(t - 2.0*math.Floor(t/2.0+0.5))
+	}
+
+	if t <= g.Stops[0].Offset {
+		return g.Stops[0].Color
+	}
+	for i, stop := range g.Stops[1:] {
+		if t < stop.Offset {
+			prev := g.Stops[i]
+			return interpolateColor(prev.Color, stop.Color, (t-prev.Offset)/(stop.Offset-prev.Offset))
+		}
+	}
+	return g.Stops[len(g.Stops)-1].Color
+}
+
+// Color returns the average color of the gradient between its start and end.
+func (g *Gradient) Color() color.RGBA {
+	if len(g.Stops) == 0 {
+		return Transparent
+	}
+
+	var r, gg, b, a float64
+	add := func(col color.RGBA, w float64) {
+		r += w * float64(col.R)
+		gg += w * float64(col.G)
+		b += w * float64(col.B)
+		a += w * float64(col.A)
+	}
+	add(g.Stops[0].Color, g.Stops[0].Offset)
+	for i, stop := range g.Stops[1:] {
+		w := stop.Offset - g.Stops[i].Offset
+		add(g.Stops[i].Color, w/2.0)
+		add(stop.Color, w/2.0)
+	}
+	add(g.Stops[len(g.Stops)-1].Color, 1.0-g.Stops[len(g.Stops)-1].Offset)
+	return color.RGBA{uint8(r + 0.5), uint8(gg + 0.5), uint8(b + 0.5), uint8(a + 0.5)}
+}
+
+// interpolateColor interpolates linearly between two colors with premultiplied alpha, where the interpolation itself is done on the colors without premultiplied alpha.
+func interpolateColor(c0, c1 color.RGBA, t float64) color.RGBA {
+	a := (1.0-t)*float64(c0.A) + t*float64(c1.A)
+	if a == 0.0 {
+		return Transparent
+	}
+	channel := func(v0, v1 uint8) uint8 {
+		var f0, f1 float64
+		if c0.A != 0 {
+			f0 = float64(v0) / float64(c0.A)
+		}
+		if c1.A != 0 {
+			f1 = float64(v1) / float64(c1.A)
+		}
+		return uint8(((1.0-t)*f0+t*f1)*a + 0.5)
+	}
+	return color.RGBA{channel(c0.R, c1.R), channel(c0.G, c1.G), channel(c0.B, c1.B), uint8(a + 0.5)}
+}
+
+// LinearGradient is a gradient paint along the line from Start to End, with lines of equal color perpendicular to it.
+type LinearGradient struct {
+	Gradient
+	Start, End Point
+}
+
+// NewLinearGradient returns a linear gradient from start to end in path coordinates. Add color stops using AddColorStop.
+func NewLinearGradient(start, end Point) *LinearGradient {
+	return &LinearGradient{
+		Gradient: Gradient{M: Identity},
+		Start:    start,
+		End:      end,
+	}
+}
+
+// At returns the color of the gradient at (x,y).
+func (g *LinearGradient) At(x, y float64) color.RGBA {
+	d := g.End.Sub(g.Start)
+	if d.IsZero() {
+		return g.ColorAt(1.0)
+	}
+	pos := g.M.Inv().Dot(Point{x, y})
+	return g.ColorAt(pos.Sub(g.Start).Dot(d) / d.Dot(d))
+}
+
+// RadialGradient is a gradient paint between two circles, the start circle with center C0 and radius R0 and the end circle with center C1 and radius R1, as in SVG (the focal and outer circle) and PDF. Each point takes the color of the interpolated circle with the largest offset that passes through it. Points that lie on none of the circles are transparent.
+type RadialGradient struct {
+	Gradient
+	C0 Point
+	R0 float64
+	C1 Point
+	R1 float64
+}
+
+// NewRadialGradient returns a radial gradient between the start and end circles in path coordinates. Add color stops using AddColorStop.
+func NewRadialGradient(c0 Point, r0 float64, c1 Point, r1 float64) *RadialGradient {
+	return &RadialGradient{
+		Gradient: Gradient{M: Identity},
+		C0:       c0,
+		R0:       r0,
+		C1:       c1,
+		R1:       r1,
+	}
+}
+
+// At returns the color of the gradient at (x,y).
+func (g *RadialGradient) At(x, y float64) color.RGBA {
+	// solve for t in |p - c(t)| = r(t), where c(t) = C0 + t*(C1-C0) and r(t) = R0 + t*(R1-R0)
+	pos := g.M.Inv().Dot(Point{x, y})
+	cd := g.C1.Sub(g.C0)
+	pd := pos.Sub(g.C0)
+	dr := g.R1 - g.R0
+	a := cd.Dot(cd) - dr*dr
+	b := pd.Dot(cd) + g.R0*dr
+	c := pd.Dot(pd) - g.R0*g.R0
+
+	t := math.NaN()
+	if Equal(a, 0.0) {
+		if !Equal(b, 0.0) {
+			t = c / (2.0 * b)
+		}
+	} else if discriminant := b*b - a*c; 0.0 <= discriminant {
+		t0 := (b + math.Sqrt(discriminant)) / a
+		t1 := (b - math.Sqrt(discriminant)) / a
+		if t0 < t1 {
+			t0, t1 = t1, t0
+		}
+		if 0.0 <= g.R0+t0*dr {
+			t = t0
+		} else if 0.0 <= g.R0+t1*dr {
+			t = t1
+		}
+	}
+	if math.IsNaN(t) || g.R0+t*dr < 0.0 {
+		return Transparent
+	}
+	return g.ColorAt(t)
+}
+
+// ConicGradient is a gradient paint that sweeps counter clockwise around a center, starting at an angle in degrees measured counter clockwise from the x-axis. It is also known as an angular or sweep gradient.
+type ConicGradient struct {
+	Gradient
+	Center Point
+	Angle  float64
+}
+
+// NewConicGradient returns a conic gradient around center in path coordinates, starting at angle in degrees. Add color stops using AddColorStop.
+func NewConicGradient(center Point, angle float64) *ConicGradient {
+	return &ConicGradient{
+		Gradient: Gradient{M: Identity},
+		Center:   center,
+		Angle:    angle,
+	}
+}
+
+// At returns the color of the gradient at (x,y).
+func (g *ConicGradient) At(x, y float64) color.RGBA {
+	pos := g.M.Inv().Dot(Point{x, y}).Sub(g.Center)
+	theta := math.Atan2(pos.Y, pos.X)*180.0/math.Pi - g.Angle
+	theta -= 360.0 * math.Floor(theta/360.0)
+	return g.ColorAt(theta / 360.0)
+}
@@ -0,0 +1,81 @@
+package canvas
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+func TestGradientColorAt(t *testing.T) {
+	g := &Gradient{}
+	test.T(t, g.ColorAt(0.5), Transparent)
+
+	g.AddColorStop(1.0, Blue)
+	g.AddColorStop(0.0, Red)
+	test.T(t, g.Stops, []Stop{{Offset: 0.0, Color: Red}, {Offset: 1.0, Color: Blue}})
+	test.T(t, g.ColorAt(-0.5), Red)
+	test.T(t, g.ColorAt(0.5), color.RGBA{128, 0, 128, 255})
+	test.T(t, g.ColorAt(1.5), Blue)
+	test.T(t, g.Color(), color.RGBA{128, 0, 128, 255})
+
+	g.Spread = RepeatSpread
+	test.T(t, g.ColorAt(1.25), g.ColorAt(0.25))
+	test.T(t, g.ColorAt(-0.75), g.ColorAt(0.25))
+	g.Spread = ReflectSpread
+	test.T(t, g.ColorAt(1.25), g.ColorAt(0.75))
+	test.T(t, g.ColorAt(-0.25), g.ColorAt(0.25))
+
+	// sharp transition
+	g = &Gradient{}
+	g.AddColorStop(0.0, Red)
+	g.AddColorStop(0.5, Red)
+	g.AddColorStop(0.5, Blue)
+	g.AddColorStop(1.0, Blue)
+	test.T(t, g.ColorAt(0.49), Red)
+	test.T(t, g.ColorAt(0.51), Blue)
+
+	// interpolate without premultiplied alpha, so that transparent black darkens the color as in SVG and PDF
+	g = &Gradient{}
+	g.AddColorStop(0.0, Red)
+	g.AddColorStop(1.0, Transparent)
+	test.T(t, g.ColorAt(0.5), color.RGBA{64, 0, 0, 128})
+
+	// CMYK colors are kept
+	cmyk := CMYK(0.0, 1.0, 1.0, 0.0)
+	g = &Gradient{}
+	g.AddColorStop(0.0, cmyk)
+	g.AddColorStop(1.0, Blue)
+	test.T(t, g.Stops, []Stop{{Offset: 0.0, Color: Red, Paint: ColorPaint{C: cmyk}}, {Offset: 1.0, Color: Blue}})
+}
+
+func TestGradientPaints(t *testing.T) {
+	linear := NewLinearGradient(Point{X: 0.0, Y: 0.0}, Point{X: 10.0, Y: 0.0})
+	linear.AddColorStop(0.0, Black)
+	linear.AddColorStop(1.0, White)
+	test.T(t, linear.At(-5.0, 3.0), Black)
+	test.T(t, linear.At(5.0, 3.0), color.RGBA{128, 128, 128, 255})
+	test.T(t, linear.At(10.0, -3.0), White)
+	linear.M = Identity.Rotate(90.0)
+	test.T(t, linear.At(3.0, 5.0), color.RGBA{128, 128, 128, 255})
+
+	radial := NewRadialGradient(Point{X: 0.0, Y: 0.0}, 0.0, Point{X: 0.0, Y: 0.0}, 10.0)
+	radial.AddColorStop(0.0, Black)
+	radial.AddColorStop(1.0, White)
+	test.T(t, radial.At(0.0, 0.0), Black)
+	test.T(t, radial.At(0.0, -5.0), color.RGBA{128, 128, 128, 255})
+	test.T(t, radial.At(20.0, 0.0), White)
+
+	// focal point outside of the end circle, leaving a region that is not painted
+	radial = NewRadialGradient(Point{X: 20.0, Y: 0.0}, 0.0, Point{X: 0.0, Y: 0.0}, 10.0)
+	radial.AddColorStop(0.0, Black)
+	radial.AddColorStop(1.0, White)
+	test.T(t, radial.At(0.0, 0.0), White)
+	test.T(t, radial.At(20.0, 10.0), Transparent)
+
+	conic := NewConicGradient(Point{X: 0.0, Y: 0.0}, 90.0)
+	conic.AddColorStop(0.0, Black)
+	conic.AddColorStop(1.0, White)
+	test.T(t, conic.At(0.0, 1.0), Black)
+	test.T(t, conic.At(0.0, -1.0), color.RGBA{128, 128, 128, 255})
+}
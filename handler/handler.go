@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/pdf"
+	"github.com/tdewolff/canvas/rasterizer"
+	"github.com/tdewolff/canvas/svg"
+)
+
+// DrawFunc returns the canvas to be served for a request.
+type DrawFunc func(*http.Request) (*canvas.Canvas, error)
+
+// Format is an output format that can be served.
+type Format string
+
+// see Format
+const (
+	SVG Format = "svg"
+	PNG Format = "png"
+	PDF Format = "pdf"
+)
+
+var mediaTypes = map[Format]string{
+	SVG: "image/svg+xml",
+	PNG: "image/png",
+	PDF: "application/pdf",
+}
+
+// Handler is an http.Handler that serves canvases. The output format is chosen by the format query parameter (svg, png or pdf), or else negotiated from the Accept header. The resolution of PNG images is set by the dpi query parameter. Responses carry an ETag based on the hash of the canvas, so that unchanged canvases are answered with 304 Not Modified without encoding them again.
+type Handler struct {
+	Draw DrawFunc
+
+	// Formats are the served formats in order of preference, the first is used when the client accepts any format.
+	Formats []Format
+
+	// DPI is the default resolution for PNG images, and MaxDPI limits the resolution that can be requested.
+	DPI, MaxDPI float64
+}
+
+// New returns a new handler that serves the canvases returned by draw as SVG, PNG or PDF.
+func New(draw DrawFunc) *Handler {
+	return &Handler{
+		Draw:    draw,
+		Formats: []Format{SVG, PNG, PDF},
+		DPI:     96.0,
+		MaxDPI:  600.0,
+	}
+}
+
+// ServeHTTP draws the canvas and writes it in the negotiated format.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	format, ok := h.negotiate(r)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		return
+	}
+
+	dpi := h.DPI
+	if format == PNG {
+		if s := r.URL.Query().Get("dpi"); s != "" {
+			var err error
+			if dpi, err = strconv.ParseFloat(s, 64); err != nil || dpi <= 0.0 || h.MaxDPI < dpi {
+				http.Error(w, fmt.Sprintf("bad dpi: %s", s), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	c, err := h.Draw(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	etag := fmt.Sprintf(`"%016x-%s`, c.Hash(), format)
+	if format == PNG {
+		etag += "-" + strconv.FormatFloat(dpi, 'f', -1, 64)
+	}
+	etag += `"`
+
+	header := w.Header()
+	header.Set("ETag", etag)
+	header.Add("Vary", "Accept")
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	var writer canvas.Writer
+	switch format {
+	case SVG:
+		writer = svg.Writer
+	case PNG:
+		writer = rasterizer.PNGWriter(canvas.DPMM(dpi) * canvas.DPI)
+	case PDF:
+		writer = pdf.Writer
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writer(buf, c); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	header.Set("Content-Type", mediaTypes[format])
+	header.Set("Content-Length", strconv.Itoa(buf.Len()))
+	if r.Method != http.MethodHead {
+		w.Write(buf.Bytes())
+	}
+}
+
+// negotiate returns the output format from the format query parameter or the Accept header.
+func (h *Handler) negotiate(r *http.Request) (Format, bool) {
+	if s := r.URL.Query().Get("format"); s != "" {
+		format := Format(strings.ToLower(s))
+		for _, f := range h.Formats {
+			if f == format {
+				return f, true
+			}
+		}
+		return "", false
+	}
+
+	accept := r.Header.Get("Accept")
+	if len(h.Formats) == 0 {
+		return "", false
+	} else if accept == "" {
+		return h.Formats[0], true
+	}
+
+	best, bestQ := Format(""), 0.0
+	for _, f := range h.Formats {
+		if q := quality(accept, mediaTypes[f]); bestQ < q {
+			best, bestQ = f, q
+		}
+	}
+	return best, 0.0 < bestQ
+}
+
+// quality returns the quality value of the most specific media range in the Accept header that matches the media type.
+func quality(accept, mediaType string) float64 {
+	q, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		s := -1
+		if mediaRange == mediaType {
+			s = 2
+		} else if mediaRange == "*/*" {
+			s = 0
+		} else if strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, mediaRange[:len(mediaRange)-1]) {
+			s = 1
+		}
+		if s <= specificity {
+			continue
+		}
+
+		specificity = s
+		q = 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+	}
+	return q
+}
+
+// etagMatch returns true if the If-None-Match header matches the ETag.
+func etagMatch(ifNoneMatch, etag string) bool {
+	for _, s := range strings.Split(ifNoneMatch, ",") {
+		s = strings.TrimPrefix(strings.TrimSpace(s), "W/")
+		if s == etag || s == "*" {
+			return true
+		}
+	}
+	return false
+}
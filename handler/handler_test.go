@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func draw(r *http.Request) (*canvas.Canvas, error) {
+	c := canvas.New(10.0, 10.0)
+	ctx := canvas.NewContext(c)
+	ctx.DrawPath(0.0, 0.0, canvas.Circle(5.0))
+	return c, nil
+}
+
+func TestHandlerFormat(t *testing.T) {
+	var tts = []struct {
+		url, accept string
+		status      int
+		contentType string
+	}{
+		{"/", "", http.StatusOK, "image/svg+xml"},
+		{"/", "*/*", http.StatusOK, "image/svg+xml"},
+		{"/", "image/png,image/*;q=0.8", http.StatusOK, "image/png"},
+		{"/", "text/html, application/pdf;q=0.9, image/svg+xml;q=0.5", http.StatusOK, "application/pdf"},
+		{"/", "image/*;q=0.5, image/svg+xml;q=0", http.StatusOK, "image/png"},
+		{"/", "text/html", http.StatusNotAcceptable, ""},
+		{"/?format=pdf", "image/png", http.StatusOK, "application/pdf"},
+		{"/?format=png&dpi=300", "", http.StatusOK, "image/png"},
+		{"/?format=png&dpi=-1", "", http.StatusBadRequest, ""},
+		{"/?format=gif", "", http.StatusNotAcceptable, ""},
+	}
+	for _, tt := range tts {
+		t.Run(tt.url+" "+tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			New(draw).ServeHTTP(rec, req)
+			test.T(t, rec.Code, tt.status)
+			if tt.status == http.StatusOK {
+				test.T(t, rec.Header().Get("Content-Type"), tt.contentType)
+				test.That(t, 0 < rec.Body.Len())
+			}
+		})
+	}
+}
+
+func TestHandlerETag(t *testing.T) {
+	h := New(draw)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	etag := rec.Header().Get("ETag")
+	test.That(t, etag != "")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	test.T(t, rec.Code, http.StatusNotModified)
+	test.T(t, rec.Body.Len(), 0)
+
+	req = httptest.NewRequest("GET", "/?format=png", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	test.T(t, rec.Code, http.StatusOK)
+}
@@ -0,0 +1,132 @@
+package canvas
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"image"
+	"math"
+)
+
+// Hash returns a hash of the canvas size and its drawing operations. Canvases that draw the same paths, texts and images with the same styles and transformations have the same hash, which makes it suitable for caching (eg. as an HTTP ETag). Fonts are identified by their name.
+func (c *Canvas) Hash() uint64 {
+	h := hasher{fnv.New64a()}
+	h.float(c.W, c.H)
+	for _, l := range c.layers {
+		h.float(l.m[0][0], l.m[0][1], l.m[0][2], l.m[1][0], l.m[1][1], l.m[1][2])
+		if l.path != nil {
+			h.string("path")
+			h.float(l.path.d...)
+			h.style(l.style)
+		} else if l.text != nil {
+			h.string("text")
+			h.text(l.text)
+		} else if l.img != nil {
+			h.string("image")
+			h.image(l.img)
+		}
+	}
+	return h.Sum64()
+}
+
+type hasher struct {
+	hash.Hash64
+}
+
+func (h hasher) float(fs ...float64) {
+	b := make([]byte, 8)
+	for _, f := range fs {
+		binary.LittleEndian.PutUint64(b, math.Float64bits(f))
+		h.Write(b)
+	}
+}
+
+func (h hasher) string(s string) {
+	h.float(float64(len(s)))
+	h.Write([]byte(s))
+}
+
+func (h hasher) style(style Style) {
+	h.Write([]byte{style.FillColor.R, style.FillColor.G, style.FillColor.B, style.FillColor.A})
+	h.Write([]byte{style.StrokeColor.R, style.StrokeColor.G, style.StrokeColor.B, style.StrokeColor.A})
+	h.float(style.StrokeWidth, style.DashOffset, float64(style.FillRule))
+	h.float(style.Dashes...)
+	h.string(fmt.Sprint(style.StrokeCapper, " ", style.StrokeJoiner))
+	h.paint(style.FillPaint)
+	h.paint(style.StrokePaint)
+}
+
+func (h hasher) paint(paint Paint) {
+	switch paint := paint.(type) {
+	case nil:
+		h.string("")
+	case *ImagePaint:
+		h.string("image")
+		h.image(paint.Image)
+		h.float(paint.M[0][0], paint.M[0][1], paint.M[0][2], paint.M[1][0], paint.M[1][1], paint.M[1][2])
+	case *Pattern:
+		h.string("pattern")
+		h.string(fmt.Sprint(paint.Tile.Hash()))
+		h.float(paint.Spacing.X, paint.Spacing.Y)
+		h.float(paint.M[0][0], paint.M[0][1], paint.M[0][2], paint.M[1][0], paint.M[1][1], paint.M[1][2])
+	default:
+		h.string(fmt.Sprintf("%T%v", paint, paint))
+	}
+}
+
+func (h hasher) face(ff FontFace) {
+	if ff.Font != nil {
+		h.string(ff.Font.name)
+	}
+	h.float(ff.Size, float64(ff.Style), float64(ff.Variant), ff.Scale, ff.Voffset, ff.FauxBold, ff.FauxItalic)
+	h.Write([]byte{ff.Color.R, ff.Color.G, ff.Color.B, ff.Color.A})
+	h.paint(ff.Paint)
+	h.string(fmt.Sprintf("%v", ff.deco))
+}
+
+func (h hasher) text(text *Text) {
+	for _, line := range text.lines {
+		h.float(line.y)
+		for _, span := range line.spans {
+			h.string(span.Text)
+			h.face(span.Face)
+			h.float(span.dx, span.width, span.SentenceSpacing, span.WordSpacing, span.GlyphSpacing)
+		}
+		for _, deco := range line.decos {
+			h.face(deco.face)
+			h.float(deco.x0, deco.x1)
+		}
+	}
+}
+
+func (h hasher) image(img image.Image) {
+	bounds := img.Bounds()
+	h.float(float64(bounds.Min.X), float64(bounds.Min.Y), float64(bounds.Max.X), float64(bounds.Max.Y))
+	switch img := img.(type) {
+	case *image.RGBA:
+		h.Write(img.Pix)
+	case *image.NRGBA:
+		h.Write(img.Pix)
+	case *image.Gray:
+		h.Write(img.Pix)
+	case *image.Paletted:
+		h.Write(img.Pix)
+		for _, col := range img.Palette {
+			r, g, b, a := col.RGBA()
+			h.float(float64(r), float64(g), float64(b), float64(a))
+		}
+	default:
+		b := make([]byte, 8)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				r, g, b2, a := img.At(x, y).RGBA()
+				binary.LittleEndian.PutUint16(b[0:], uint16(r))
+				binary.LittleEndian.PutUint16(b[2:], uint16(g))
+				binary.LittleEndian.PutUint16(b[4:], uint16(b2))
+				binary.LittleEndian.PutUint16(b[6:], uint16(a))
+				h.Write(b)
+			}
+		}
+	}
+}
@@ -39,6 +39,11 @@ func (r *htmlCanvas) Size() (float64, float64) {
 }
 
 func (r *htmlCanvas) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix) {
+	if style.FillPaint != nil || style.StrokePaint != nil {
+		canvas.RenderPaintAsImage(r, path, style, m)
+		return
+	}
+
 	if path.Empty() {
 		return
 	}
@@ -60,13 +65,13 @@ func (r *htmlCanvas) RenderPath(path *canvas.Path, style canvas.Style, m canvas.
 		r.ctx.Call("closePath")
 	})
 
-	if style.FillColor.A != 0 {
+	if style.HasFill() {
 		if style.FillColor != r.style.FillColor {
 			r.ctx.Set("fillStyle", canvas.CSSColor(style.FillColor).String())
 		}
 		r.ctx.Call("fill")
 	}
-	if style.StrokeColor.A != 0 && 0.0 < style.StrokeWidth {
+	if style.HasStroke() {
 		if style.StrokeCapper != r.style.StrokeCapper {
 			if _, ok := style.StrokeCapper.(canvas.RoundCapper); ok {
 				r.ctx.Set("lineCap", "round")
@@ -0,0 +1,198 @@
+package canvas
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"sync"
+
+	"golang.org/x/image/vector"
+)
+
+// PaintResolution is the resolution at which paths are rasterized by renderers that can not draw their paints natively, see RenderPaintAsImage.
+var PaintResolution = 150.0 * DPI
+
+// Paint is used to fill or stroke paths with something else than a solid color, such as an image, gradient or pattern. Paints are defined in the coordinate system of the path, that is before applying the view and the position at which the path is drawn, so that they move along with the path. Renderers draw paints natively when possible, otherwise they rasterize the path using RenderPaintAsImage, and renderers that support neither draw the path using the paint's average color.
+type Paint interface {
+	// At returns the color of the paint at (x,y) in path coordinates.
+	At(x, y float64) color.RGBA
+
+	// Color returns the average color of the paint, which is used by renderers that can draw neither the paint nor images.
+	Color() color.RGBA
+}
+
+// ColorPaint is a paint of a solid color of any color model. Context.SetFillColor and Context.SetStrokeColor set it for CMYK colors so that renderers for print can use the CMYK values, other renderers draw it as a solid color.
+type ColorPaint struct {
+	C color.Color
+}
+
+// At returns the color of the paint.
+func (p ColorPaint) At(x, y float64) color.RGBA {
+	return toRGBA(p.C)
+}
+
+// Color returns the color of the paint.
+func (p ColorPaint) Color() color.RGBA {
+	return toRGBA(p.C)
+}
+
+// paintEqual returns true if both paints are equal, where paints of pointer types are compared by pointer and other paints by value. Unlike ==, it does not panic for paints that hold values that are not comparable.
+func paintEqual(a, b Paint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	} else if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	} else if reflect.TypeOf(a).Kind() == reflect.Ptr {
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// ImagePaint is a paint of an image that is placed by a transformation matrix, which maps image pixels to path coordinates with the bottom-left of the image at the origin, as for Context.DrawImage. The paint is transparent outside the image.
+type ImagePaint struct {
+	Image image.Image
+	M     Matrix
+
+	colorOnce sync.Once
+	color     color.RGBA
+}
+
+// NewImagePaint returns a paint of an image with the given resolution in dots-per-millimeter, with the bottom-left of the image at the origin.
+func NewImagePaint(img image.Image, dpm float64) *ImagePaint {
+	return &ImagePaint{
+		Image: img,
+		M:     Identity.Scale(1.0/dpm, 1.0/dpm),
+	}
+}
+
+// At returns the color of the nearest image pixel at (x,y).
+func (p *ImagePaint) At(x, y float64) color.RGBA {
+	bounds := p.Image.Bounds()
+	pos := p.M.Inv().Dot(Point{x, y})
+	if pos.X < 0.0 || pos.Y < 0.0 || float64(bounds.Dx()) <= pos.X || float64(bounds.Dy()) <= pos.Y {
+		return Transparent
+	}
+	return color.RGBAModel.Convert(p.Image.At(bounds.Min.X+int(pos.X), bounds.Max.Y-1-int(pos.Y))).(color.RGBA)
+}
+
+// Color returns the average color of the image. It is computed once and is safe for concurrent use.
+func (p *ImagePaint) Color() color.RGBA {
+	p.colorOnce.Do(func() {
+		p.color = averageColor(p.Image)
+	})
+	return p.color
+}
+
+// averageColor returns the average color of all pixels of an image.
+func averageColor(img image.Image) color.RGBA {
+	bounds := img.Bounds()
+	n := uint64(bounds.Dx()) * uint64(bounds.Dy())
+	if n == 0 {
+		return Transparent
+	}
+	var r, g, b, a uint64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			cr, cg, cb, ca := img.At(x, y).RGBA()
+			r += uint64(cr)
+			g += uint64(cg)
+			b += uint64(cb)
+			a += uint64(ca)
+		}
+	}
+	return color.RGBA{uint8(r / n >> 8), uint8(g / n >> 8), uint8(b / n >> 8), uint8(a / n >> 8)}
+}
+
+// PaintImage returns an image of infinite size that shows the paint as seen through the transformation matrix m, which maps path coordinates to pixel coordinates (with the y-axis pointing down). It can be used by raster renderers as the source image when drawing a path. The tile of a pattern is rasterized once when calling PaintImage.
+func PaintImage(paint Paint, m Matrix) image.Image {
+	if p, ok := paint.(ColorPaint); ok {
+		return image.NewUniform(p.Color())
+	} else if p, ok := paint.(*Pattern); ok {
+		paint = p.snapshot(nil)
+	}
+	return paintImage{paint, m.Inv()}
+}
+
+type paintImage struct {
+	paint Paint
+	inv   Matrix
+}
+
+func (img paintImage) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (img paintImage) Bounds() image.Rectangle {
+	return image.Rect(-1e9, -1e9, 1e9, 1e9)
+}
+
+func (img paintImage) At(x, y int) color.Color {
+	pos := img.inv.Dot(Point{float64(x) + 0.5, float64(y) + 0.5})
+	return img.paint.At(pos.X, pos.Y)
+}
+
+// RenderPaintAsImage renders a path for renderers that can not draw its paints natively but can draw images. The fill or stroke that uses a paint is rasterized at PaintResolution and rendered as an image, while a fill or stroke of a solid color is rendered as a path.
+func RenderPaintAsImage(r Renderer, path *Path, style Style, m Matrix) {
+	// color paints are drawn using their solid color
+	if _, ok := style.FillPaint.(ColorPaint); ok {
+		style.FillPaint = nil
+	}
+	if _, ok := style.StrokePaint.(ColorPaint); ok {
+		style.StrokePaint = nil
+	}
+	if style.FillPaint == nil && style.StrokePaint == nil {
+		r.RenderPath(path, style, m)
+		return
+	}
+
+	fill := style.HasFill()
+	stroke := style.HasStroke()
+
+	if fill {
+		if style.FillPaint != nil {
+			renderPaintAsImage(r, path, style.FillPaint, m)
+		} else {
+			fillStyle := style
+			fillStyle.StrokeColor = Transparent
+			fillStyle.StrokePaint = nil
+			r.RenderPath(path, fillStyle, m)
+		}
+	}
+	if stroke {
+		if style.StrokePaint != nil {
+			if 0 < len(style.Dashes) {
+				path = path.Dash(style.DashOffset, style.Dashes...)
+			}
+			path = path.Stroke(style.StrokeWidth, style.StrokeCapper, style.StrokeJoiner)
+			renderPaintAsImage(r, path, style.StrokePaint, m)
+		} else {
+			strokeStyle := style
+			strokeStyle.FillColor = Transparent
+			strokeStyle.FillPaint = nil
+			r.RenderPath(path, strokeStyle, m)
+		}
+	}
+}
+
+// renderPaintAsImage fills a path with a paint on an image the size of the path's bounds, and renders the image.
+func renderPaintAsImage(r Renderer, path *Path, paint Paint, m Matrix) {
+	resolution := float64(PaintResolution)
+	bounds := path.Transform(m).Bounds()
+	x0 := float64(int(bounds.X*resolution)) / resolution
+	y0 := float64(int(bounds.Y*resolution)) / resolution
+	if bounds.X < x0 {
+		x0 -= 1.0 / resolution
+	}
+	if bounds.Y < y0 {
+		y0 -= 1.0 / resolution
+	}
+	w := int((bounds.X+bounds.W-x0)*resolution) + 1
+	h := int((bounds.Y+bounds.H-y0)*resolution) + 1
+
+	m = Identity.Translate(-x0, -y0).Mul(m)
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	ras := vector.NewRasterizer(w, h)
+	path.Transform(m).ToRasterizer(ras, resolution)
+	ras.Draw(img, img.Bounds(), PaintImage(paint, Identity.Translate(0.0, float64(h)).Scale(resolution, -resolution).Mul(m)), image.Point{})
+	r.RenderImage(img, Identity.Translate(x0, y0).Scale(1.0/resolution, 1.0/resolution))
+}
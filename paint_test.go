@@ -0,0 +1,143 @@
+package canvas
+
+import (
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+func TestImagePaint(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, Red) // top-left
+	img.Set(1, 0, Green)
+	img.Set(0, 1, Blue) // bottom-left
+	img.Set(1, 1, Blue)
+
+	paint := NewImagePaint(img, 0.5)
+	test.T(t, paint.At(1.0, 1.0), Blue)
+	test.T(t, paint.At(1.0, 3.0), Red)
+	test.T(t, paint.At(3.0, 3.0), Green)
+	test.T(t, paint.At(-1.0, 1.0), Transparent)
+	test.T(t, paint.At(1.0, 4.0), Transparent)
+	test.T(t, paint.Color(), color.RGBA{63, 32, 127, 255})
+
+	src := PaintImage(paint, Identity.Translate(0.0, 4.0).Scale(1.0, -1.0)) // pixel coordinates with y down
+	test.T(t, src.At(0, 0), color.Color(Red))
+	test.T(t, src.At(3, 3), color.Color(Blue))
+}
+
+func TestContextPaint(t *testing.T) {
+	paint := NewImagePaint(image.NewRGBA(image.Rect(0, 0, 1, 1)), 1.0)
+
+	ctx := NewContext(New(10, 10))
+	ctx.SetFillPaint(paint)
+	test.T(t, ctx.Style.FillPaint, Paint(paint))
+	ctx.SetFillPaint(ColorPaint{C: Red})
+	test.T(t, ctx.Style.FillPaint, nil)
+	test.T(t, ctx.Style.FillColor, Red)
+
+	ctx.SetStrokePaint(paint)
+	test.T(t, ctx.Style.StrokePaint, Paint(paint))
+	ctx.SetStrokeColor(Blue)
+	test.T(t, ctx.Style.StrokePaint, nil)
+
+	ctx.SetFillColor(Green)
+	ctx.SetFillPaint(paint)
+	ctx.SetFillPaint(nil)
+	test.T(t, ctx.Style.FillPaint, nil)
+	test.T(t, ctx.Style.FillColor, Transparent)
+	ctx.SetStrokePaint(nil)
+	test.T(t, ctx.Style.StrokePaint, nil)
+	test.T(t, ctx.Style.StrokeColor, Blue)
+
+	ctx.SetFillColor(CMYK(0.0, 1.0, 1.0, 0.0))
+	ctx.SetFillPaint(nil)
+	test.T(t, ctx.Style.FillColor, Red)
+}
+
+func TestContextPaintTransparentAverage(t *testing.T) {
+	// the average color of the paint is transparent, but the paint is still drawn
+	img := image.NewRGBA(image.Rect(0, 0, 256, 1))
+	img.Set(0, 0, Red)
+	paint := NewImagePaint(img, 1.0)
+	test.T(t, paint.Color().A, uint8(0))
+
+	c := New(10, 10)
+	ctx := NewContext(c)
+	ctx.SetFillPaint(paint)
+	test.That(t, ctx.Style.HasFill(), "expected fill")
+	ctx.DrawPath(0.0, 0.0, Rectangle(10.0, 10.0))
+	test.T(t, len(c.layers), 1)
+}
+
+type slicePaint []color.RGBA
+
+func (p slicePaint) At(x, y float64) color.RGBA { return p[0] }
+func (p slicePaint) Color() color.RGBA          { return p[0] }
+
+type sliceColor []uint8
+
+func (c sliceColor) RGBA() (uint32, uint32, uint32, uint32) { return 0, 0, 0, 0xffff }
+
+func TestPaintEqual(t *testing.T) {
+	paint := NewImagePaint(image.NewRGBA(image.Rect(0, 0, 1, 1)), 1.0)
+	test.That(t, paintEqual(nil, nil))
+	test.That(t, !paintEqual(paint, nil))
+	test.That(t, paintEqual(paint, paint))
+	test.That(t, !paintEqual(paint, NewImagePaint(paint.Image, 1.0)))
+	test.That(t, paintEqual(ColorPaint{C: CMYK(0.0, 1.0, 1.0, 0.0)}, ColorPaint{C: CMYK(0.0, 1.0, 1.0, 0.0)}))
+	test.That(t, !paintEqual(ColorPaint{C: CMYK(0.0, 1.0, 1.0, 0.0)}, ColorPaint{C: Red}))
+
+	// values that are not comparable do not panic
+	test.That(t, paintEqual(slicePaint{Red}, slicePaint{Red}))
+	test.That(t, paintEqual(ColorPaint{C: sliceColor{1}}, ColorPaint{C: sliceColor{1}}))
+	test.That(t, !paintEqual(ColorPaint{C: sliceColor{1}}, ColorPaint{C: sliceColor{2}}))
+}
+
+func TestImagePaintColorConcurrent(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, Red)
+	paint := NewImagePaint(img, 1.0)
+
+	var wg sync.WaitGroup
+	colors := make([]color.RGBA, 4)
+	for i := range colors {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			colors[i] = paint.Color()
+		}(i)
+	}
+	wg.Wait()
+	for _, col := range colors {
+		test.T(t, col, Red)
+	}
+}
+
+func TestRenderPaintAsImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, Red)
+
+	style := DefaultStyle
+	style.FillPaint = NewImagePaint(img, 0.1)
+	style.StrokeColor = Blue
+
+	c := New(10, 10)
+	RenderPaintAsImage(c, Rectangle(2.0, 2.0), style, Identity.Translate(1.0, 1.0))
+	test.T(t, len(c.layers), 2)
+	test.That(t, c.layers[0].img != nil, "expected fill to be rendered as image")
+	test.T(t, c.layers[1].style.FillColor, Transparent)
+	test.T(t, c.layers[1].style.StrokeColor, Blue)
+
+	res := float64(PaintResolution)
+	raster := c.layers[0].img.(*image.RGBA)
+	x0 := float64(int(res)) / res // aligned to pixels
+	n := int((3.0-x0)*res) + 1
+	test.T(t, raster.Bounds(), image.Rect(0, 0, n, n))
+	test.T(t, raster.At(n/2, n/2), color.Color(Red))
+	test.T(t, raster.At(0, 0).(color.RGBA).A < 255, true) // partially covered
+	test.T(t, c.layers[0].m, Identity.Translate(x0, x0).Scale(1.0/res, 1.0/res))
+}
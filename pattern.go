@@ -0,0 +1,191 @@
+package canvas
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+	"golang.org/x/image/vector"
+)
+
+// Pattern is a paint that repeats a tile, which is a canvas that may contain paths, text and images. The tiles are placed next to each other with Spacing in between, starting with the bottom-left of a tile at the origin, and the transformation matrix maps the pattern's coordinates to path coordinates. Renderers that do not support patterns natively use a tile rasterized at PaintResolution.
+type Pattern struct {
+	Tile    *Canvas
+	Spacing Point
+	M       Matrix
+}
+
+// NewPattern returns a pattern that repeats a tile.
+func NewPattern(tile *Canvas) *Pattern {
+	return &Pattern{
+		Tile: tile,
+		M:    Identity,
+	}
+}
+
+// NewImagePattern returns a pattern that repeats an image with the given resolution in dots-per-millimeter.
+func NewImagePattern(img image.Image, dpm float64) *Pattern {
+	size := img.Bounds().Size()
+	tile := New(float64(size.X)/dpm, float64(size.Y)/dpm)
+	NewContext(tile).DrawImage(0.0, 0.0, img, dpm)
+	return NewPattern(tile)
+}
+
+// NewHatchPattern returns a pattern of parallel lines of the given color and width, at a distance from each other and rotated counter clockwise by angle in degrees from the x-axis.
+func NewHatchPattern(col color.Color, angle, distance, width float64) *Pattern {
+	tile := New(distance, distance)
+	ctx := NewContext(tile)
+	ctx.SetFillColor(col)
+	ctx.DrawPath(0.0, (distance-width)/2.0, Rectangle(distance, width))
+	pattern := NewPattern(tile)
+	pattern.M = Identity.Rotate(angle)
+	return pattern
+}
+
+// NewCheckerboardPattern returns a pattern of squares of the given size alternating between two colors.
+func NewCheckerboardPattern(col0, col1 color.Color, size float64) *Pattern {
+	tile := New(2.0*size, 2.0*size)
+	ctx := NewContext(tile)
+	ctx.SetFillColor(col1)
+	ctx.DrawPath(0.0, 0.0, Rectangle(2.0*size, 2.0*size))
+	ctx.SetFillColor(col0)
+	ctx.DrawPath(0.0, 0.0, Rectangle(size, size))
+	ctx.DrawPath(size, size, Rectangle(size, size))
+	return NewPattern(tile)
+}
+
+// At returns the color of the pattern at (x,y). The tile is rasterized for every call, use PaintImage to look up many colors.
+func (p *Pattern) At(x, y float64) color.RGBA {
+	return p.snapshot(nil).At(x, y)
+}
+
+// Color returns the average color of the pattern, including the spacing between tiles.
+func (p *Pattern) Color() color.RGBA {
+	return p.snapshot(nil).Color()
+}
+
+// snapshot returns the pattern with its tile rasterized at approximately PaintResolution, so that the tile fits an integer number of pixels. Patterns in active are being rasterized by the caller, they are not drawn when the tile uses them to prevent endless recursion.
+func (p *Pattern) snapshot(active []*Pattern) *patternSnapshot {
+	w := int(p.Tile.W*float64(PaintResolution) + 0.5)
+	h := int(p.Tile.H*float64(PaintResolution) + 0.5)
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	p.Tile.Render(&tileRenderer{img, p.Tile.W, p.Tile.H, append(active[:len(active):len(active)], p)})
+	return &patternSnapshot{
+		w:   p.Tile.W,
+		h:   p.Tile.H,
+		dx:  p.Tile.W + p.Spacing.X,
+		dy:  p.Tile.H + p.Spacing.Y,
+		inv: p.M.Inv(),
+		img: img,
+	}
+}
+
+// patternSnapshot is a pattern with its tile rasterized, which is a paint that does not change when the pattern or its tile changes. Renderers use it through PaintImage so that the tile is rasterized once per path.
+type patternSnapshot struct {
+	w, h   float64 // tile size
+	dx, dy float64 // tile step
+	inv    Matrix
+	img    *image.RGBA
+}
+
+// At returns the color of the pattern at (x,y).
+func (s *patternSnapshot) At(x, y float64) color.RGBA {
+	if s.dx <= 0.0 || s.dy <= 0.0 {
+		return Transparent
+	}
+	pos := s.inv.Dot(Point{x, y})
+	pos.X -= s.dx * math.Floor(pos.X/s.dx)
+	pos.Y -= s.dy * math.Floor(pos.Y/s.dy)
+	if s.w <= pos.X || s.h <= pos.Y {
+		return Transparent
+	}
+
+	size := s.img.Bounds().Size()
+	ix := int(pos.X / s.w * float64(size.X))
+	iy := size.Y - 1 - int(pos.Y/s.h*float64(size.Y))
+	if ix < 0 || size.X <= ix || iy < 0 || size.Y <= iy {
+		return Transparent
+	}
+	return s.img.RGBAAt(ix, iy)
+}
+
+// Color returns the average color of the pattern, including the spacing between tiles.
+func (s *patternSnapshot) Color() color.RGBA {
+	if s.dx <= 0.0 || s.dy <= 0.0 {
+		return Transparent
+	}
+	col := averageColor(s.img)
+	f := s.w * s.h / (s.dx * s.dy)
+	return color.RGBA{uint8(float64(col.R)*f + 0.5), uint8(float64(col.G)*f + 0.5), uint8(float64(col.B)*f + 0.5), uint8(float64(col.A)*f + 0.5)}
+}
+
+// tileRenderer is a minimal raster renderer used to rasterize pattern tiles, which stretches the tile of width and height in millimeters to the image.
+type tileRenderer struct {
+	img           *image.RGBA
+	width, height float64
+	active        []*Pattern // patterns whose tiles are being rasterized
+}
+
+func (r *tileRenderer) Size() (float64, float64) {
+	return r.width, r.height
+}
+
+// view returns the transformation from the tile to pixel coordinates, with the y-axis pointing up.
+func (r *tileRenderer) view() Matrix {
+	size := r.img.Bounds().Size()
+	return Identity.Scale(float64(size.X)/r.width, float64(size.Y)/r.height)
+}
+
+func (r *tileRenderer) RenderPath(path *Path, style Style, m Matrix) {
+	size := r.img.Bounds().Size()
+	m = r.view().Mul(m)
+	rasterize := func(path *Path, col color.RGBA, paint Paint) {
+		var src image.Image = image.NewUniform(col)
+		if pattern, ok := paint.(*Pattern); ok {
+			for _, p := range r.active {
+				if p == pattern {
+					return // the pattern is used in its own tile
+				}
+			}
+			paint = pattern.snapshot(r.active)
+		}
+		if paint != nil {
+			src = PaintImage(paint, Identity.Translate(0.0, float64(size.Y)).ReflectY().Mul(m))
+		}
+		ras := vector.NewRasterizer(size.X, size.Y)
+		path.Transform(m).ToRasterizer(ras, 1.0)
+		ras.Draw(r.img, r.img.Bounds(), src, image.Point{})
+	}
+
+	if style.HasFill() {
+		rasterize(path, style.FillColor, style.FillPaint)
+	}
+	if style.HasStroke() {
+		if 0 < len(style.Dashes) {
+			path = path.Dash(style.DashOffset, style.Dashes...)
+		}
+		path = path.Stroke(style.StrokeWidth, style.StrokeCapper, style.StrokeJoiner)
+		rasterize(path, style.StrokeColor, style.StrokePaint)
+	}
+}
+
+func (r *tileRenderer) RenderText(text *Text, m Matrix) {
+	RenderTextAsPath(r, text, m)
+}
+
+func (r *tileRenderer) RenderImage(img image.Image, m Matrix) {
+	// map source pixels (y down) to image space (y up), to the tile and to destination pixels (y down)
+	bounds := img.Bounds()
+	h := float64(r.img.Bounds().Size().Y)
+	m = Identity.Translate(0.0, h).ReflectY().Mul(r.view()).Mul(m).Translate(0.0, float64(bounds.Dy())).ReflectY().Translate(-float64(bounds.Min.X), -float64(bounds.Min.Y))
+	aff3 := f64.Aff3{m[0][0], m[0][1], m[0][2], m[1][0], m[1][1], m[1][2]}
+	draw.ApproxBiLinear.Transform(r.img, aff3, img, bounds, draw.Over, nil)
+}
@@ -0,0 +1,94 @@
+package canvas
+
+import (
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+func TestPattern(t *testing.T) {
+	pattern := NewCheckerboardPattern(Black, White, 1.0)
+	test.T(t, pattern.At(0.5, 0.5), Black)
+	test.T(t, pattern.At(1.5, 0.5), White)
+	test.T(t, pattern.At(0.5, 1.5), White)
+	test.T(t, pattern.At(2.5, 2.5), Black)
+	test.T(t, pattern.At(-0.5, 0.5), White)
+	test.T(t, pattern.Color(), color.RGBA{127, 127, 127, 255})
+
+	pattern.Spacing = Point{X: 2.0, Y: 0.0}
+	test.T(t, pattern.At(2.5, 0.5), Transparent)
+	test.T(t, pattern.At(4.5, 0.5), Black)
+	test.T(t, pattern.Color(), color.RGBA{64, 64, 64, 128})
+
+	pattern.M = Identity.Scale(2.0, 2.0)
+	test.T(t, pattern.At(1.0, 1.0), Black)
+	test.T(t, pattern.At(3.0, 1.0), White)
+}
+
+func TestPatternTile(t *testing.T) {
+	pattern := NewCheckerboardPattern(Black, White, 1.0)
+	test.T(t, pattern.At(0.5, 0.5), Black)
+
+	// replacing the tile rasterizes the new tile
+	pattern.Tile = NewCheckerboardPattern(Red, White, 1.0).Tile
+	test.T(t, pattern.At(0.5, 0.5), Red)
+
+	var wg sync.WaitGroup
+	colors := make([]color.RGBA, 4)
+	for i := range colors {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			colors[i] = pattern.At(1.5, 1.5)
+		}(i)
+	}
+	wg.Wait()
+	for _, col := range colors {
+		test.T(t, col, Red)
+	}
+
+	// drawing onto the tile after first use is seen by the pattern
+	NewContext(pattern.Tile).DrawPath(0.0, 0.0, Rectangle(2.0, 2.0))
+	test.T(t, pattern.At(1.5, 0.5), Black)
+
+	// renderers use a snapshot that is rasterized once
+	src := PaintImage(pattern, Identity)
+	pattern.Tile = NewCheckerboardPattern(Blue, White, 1.0).Tile
+	test.T(t, src.At(0, 0), color.Color(Black))
+	test.T(t, pattern.At(0.5, 0.5), Blue)
+}
+
+func TestPatternRecursion(t *testing.T) {
+	tile := New(2.0, 2.0)
+	pattern := NewPattern(tile)
+	ctx := NewContext(tile)
+	ctx.SetFillColor(Red)
+	ctx.DrawPath(0.0, 0.0, Rectangle(1.0, 1.0))
+	ctx.SetFillPaint(pattern) // the tile uses its own pattern, which is not drawn
+	ctx.DrawPath(0.0, 0.0, Rectangle(2.0, 2.0))
+	test.T(t, pattern.At(0.5, 0.5), Red)
+	test.T(t, pattern.At(1.5, 1.5), Transparent)
+}
+
+func TestImagePattern(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, Red)
+	img.Set(1, 0, Blue)
+
+	// the image is interpolated when rasterizing the tile
+	pattern := NewImagePattern(img, 0.5) // 4x2 mm
+	test.T(t, pattern.Tile.W, 4.0)
+	test.That(t, 240 < pattern.At(1.0, 1.0).R, "expected red:", pattern.At(1.0, 1.0))
+	test.That(t, 240 < pattern.At(3.0, 1.0).B, "expected blue:", pattern.At(3.0, 1.0))
+	test.That(t, 240 < pattern.At(5.0, 3.0).R, "expected red:", pattern.At(5.0, 3.0))
+}
+
+func TestHatchPattern(t *testing.T) {
+	pattern := NewHatchPattern(Black, 90.0, 2.0, 1.0)
+	test.T(t, pattern.At(1.0, 0.1), Black) // vertical lines at x=1 mod 2
+	test.T(t, pattern.At(0.1, 0.1), Transparent)
+	test.T(t, pattern.At(3.0, 5.1), Black)
+}
@@ -0,0 +1,845 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+)
+
+// pdfKeyword is a bare keyword, such as an operator in a content stream.
+type pdfKeyword string
+
+// pdfRawStream is a stream whose data is already encoded with the filters in its dictionary.
+type pdfRawStream pdfStream
+
+func isWhitespace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isDelimiter(c byte) bool {
+	return c == '(' || c == ')' || c == '<' || c == '>' || c == '[' || c == ']' || c == '{' || c == '}' || c == '/' || c == '%'
+}
+
+// pdfLexer parses PDF values from a byte slice. Names are returned as pdfName, indirect references as pdfRef, strings as string, numbers as int or float64, and other keywords as pdfKeyword.
+type pdfLexer struct {
+	b    []byte
+	pos  int
+	refs bool // parse indirect references, disabled for content streams
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.b) {
+		if c := l.b[l.pos]; c == '%' {
+			for l.pos < len(l.b) && l.b[l.pos] != '\n' && l.b[l.pos] != '\r' {
+				l.pos++
+			}
+		} else if isWhitespace(c) {
+			l.pos++
+		} else {
+			return
+		}
+	}
+}
+
+func (l *pdfLexer) hasPrefix(s string) bool {
+	return bytes.HasPrefix(l.b[l.pos:], []byte(s))
+}
+
+func (l *pdfLexer) regular() []byte {
+	start := l.pos
+	for l.pos < len(l.b) && !isWhitespace(l.b[l.pos]) && !isDelimiter(l.b[l.pos]) {
+		l.pos++
+	}
+	return l.b[start:l.pos]
+}
+
+func (l *pdfLexer) value() (interface{}, error) {
+	l.skipSpace()
+	if len(l.b) <= l.pos {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	switch c := l.b[l.pos]; c {
+	case '/':
+		l.pos++
+		name := l.regular()
+		if bytes.IndexByte(name, '#') == -1 {
+			return pdfName(name), nil
+		}
+		b := make([]byte, 0, len(name))
+		for i := 0; i < len(name); i++ {
+			if name[i] == '#' && i+2 < len(name) {
+				if n, err := strconv.ParseUint(string(name[i+1:i+3]), 16, 8); err == nil {
+					b = append(b, byte(n))
+					i += 2
+					continue
+				}
+			}
+			b = append(b, name[i])
+		}
+		return pdfName(b), nil
+	case '(':
+		return l.literalString()
+	case '<':
+		if l.hasPrefix("<<") {
+			l.pos += 2
+			dict := pdfDict{}
+			for {
+				l.skipSpace()
+				if l.hasPrefix(">>") {
+					l.pos += 2
+					return dict, nil
+				}
+				key, err := l.value()
+				if err != nil {
+					return nil, err
+				}
+				name, ok := key.(pdfName)
+				if !ok {
+					return nil, fmt.Errorf("bad dictionary key at offset %d", l.pos)
+				}
+				val, err := l.value()
+				if err != nil {
+					return nil, err
+				}
+				dict[name] = val
+			}
+		}
+		l.pos++
+		end := bytes.IndexByte(l.b[l.pos:], '>')
+		if end == -1 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		s := make([]byte, 0, end+1)
+		for _, c := range l.b[l.pos : l.pos+end] {
+			if !isWhitespace(c) {
+				s = append(s, c)
+			}
+		}
+		if len(s)%2 == 1 {
+			s = append(s, '0')
+		}
+		l.pos += end + 1
+		b, err := hex.DecodeString(string(s))
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case '[':
+		l.pos++
+		array := pdfArray{}
+		for {
+			l.skipSpace()
+			if l.hasPrefix("]") {
+				l.pos++
+				return array, nil
+			}
+			val, err := l.value()
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, val)
+		}
+	case '+', '-', '.', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		num := l.regular()
+		if i, err := strconv.Atoi(string(num)); err == nil {
+			if l.refs && 0 < i {
+				// look ahead for an indirect reference: int int R
+				pos := l.pos
+				l.skipSpace()
+				gen := l.regular()
+				l.skipSpace()
+				if _, err := strconv.Atoi(string(gen)); err == nil && 0 < len(gen) && l.hasPrefix("R") && (len(l.b) == l.pos+1 || isWhitespace(l.b[l.pos+1]) || isDelimiter(l.b[l.pos+1])) {
+					l.pos++
+					return pdfRef(i), nil
+				}
+				l.pos = pos
+			}
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(string(num), 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number at offset %d", l.pos)
+		}
+		return f, nil
+	case ')', '>', ']', '{', '}':
+		l.pos++
+		return pdfKeyword(c), nil
+	}
+
+	keyword := l.regular()
+	switch string(keyword) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	return pdfKeyword(keyword), nil
+}
+
+func (l *pdfLexer) literalString() (string, error) {
+	l.pos++ // (
+	b := []byte{}
+	depth := 0
+	for l.pos < len(l.b) {
+		c := l.b[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return string(b), nil
+			}
+			depth--
+		case '\r':
+			// end-of-line markers are read as a newline
+			if l.pos < len(l.b) && l.b[l.pos] == '\n' {
+				l.pos++
+			}
+			c = '\n'
+		case '\\':
+			if len(l.b) <= l.pos {
+				return "", io.ErrUnexpectedEOF
+			}
+			c = l.b[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				// line continuation
+				if c == '\r' && l.pos < len(l.b) && l.b[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			default:
+				if '0' <= c && c <= '7' {
+					n := int(c - '0')
+					for i := 0; i < 2 && l.pos < len(l.b) && '0' <= l.b[l.pos] && l.b[l.pos] <= '7'; i++ {
+						n = n*8 + int(l.b[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(n)
+				}
+			}
+		}
+		b = append(b, c)
+	}
+	return "", io.ErrUnexpectedEOF
+}
+
+////////////////////////////////////////////////////////////////
+
+// pdfReader reads the objects of an existing PDF file, as needed to import its pages. Objects are parsed lazily using the cross-reference table, and both cross-reference streams and object streams are supported. Encrypted files are not supported.
+type pdfReader struct {
+	b       []byte
+	trailer pdfDict
+	xref    map[pdfRef]pdfXref
+	objs    map[pdfRef]interface{}
+	objStms map[pdfRef][]byte
+}
+
+// pdfXref is the location of an object, either at a byte offset or at an index in an object stream.
+type pdfXref struct {
+	offset int
+	stream pdfRef
+}
+
+func newPDFReader(r io.Reader) (*pdfReader, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	} else if !bytes.HasPrefix(b, []byte("%PDF-")) {
+		return nil, fmt.Errorf("not a PDF file")
+	}
+
+	reader := &pdfReader{
+		b:       b,
+		xref:    map[pdfRef]pdfXref{},
+		objs:    map[pdfRef]interface{}{},
+		objStms: map[pdfRef][]byte{},
+	}
+
+	if err := reader.readXrefs(); err != nil {
+		// damaged file, rebuild the cross-reference table by scanning for objects
+		reader.xref = map[pdfRef]pdfXref{}
+		reader.trailer = nil
+		if err := reader.reconstructXref(); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := reader.trailer["Encrypt"]; ok {
+		return nil, fmt.Errorf("encrypted PDF files are not supported")
+	}
+	return reader, nil
+}
+
+// readXrefs reads the cross-reference sections starting from the last one.
+func (r *pdfReader) readXrefs() error {
+	i := bytes.LastIndex(r.b, []byte("startxref"))
+	if i == -1 {
+		return fmt.Errorf("startxref not found")
+	}
+	l := &pdfLexer{b: r.b, pos: i + len("startxref")}
+	offset, err := l.value()
+	if err != nil {
+		return err
+	}
+
+	visited := map[int]bool{}
+	for {
+		pos, ok := offset.(int)
+		if !ok || pos < 0 || len(r.b) <= pos || visited[pos] {
+			return fmt.Errorf("bad cross-reference offset")
+		}
+		visited[pos] = true
+
+		trailer, err := r.readXref(pos)
+		if err != nil {
+			return err
+		}
+		if r.trailer == nil {
+			r.trailer = trailer
+		}
+		if xrefStm, ok := trailer["XRefStm"].(int); ok && !visited[xrefStm] {
+			// hybrid-reference file
+			visited[xrefStm] = true
+			if _, err := r.readXref(xrefStm); err != nil {
+				return err
+			}
+		}
+		if offset, ok = trailer["Prev"]; !ok {
+			return nil
+		}
+	}
+}
+
+// reconstructXref finds all objects by scanning the file, and uses the last trailer or else the last document catalog.
+func (r *pdfReader) reconstructXref() error {
+	var catalog pdfRef
+	for i := 0; i < len(r.b); {
+		j := bytes.Index(r.b[i:], []byte("obj"))
+		if j == -1 {
+			break
+		}
+		end := i + j
+		i = end + 3
+
+		// go back over the object and generation numbers
+		start := end
+		for k := 0; k < 2; k++ {
+			for 0 < start && isWhitespace(r.b[start-1]) {
+				start--
+			}
+			digits := start
+			for 0 < start && '0' <= r.b[start-1] && r.b[start-1] <= '9' {
+				start--
+			}
+			if digits == start {
+				start = -1
+				break
+			}
+		}
+		if start < 0 || 0 < start && !isWhitespace(r.b[start-1]) && !isDelimiter(r.b[start-1]) {
+			continue
+		}
+
+		l := &pdfLexer{b: r.b, pos: start}
+		if num, ok := l.readInt(); ok && 0 < num {
+			r.xref[pdfRef(num)] = pdfXref{offset: start}
+			if dict, ok := r.resolve(pdfRef(num)).(pdfDict); ok && dict["Type"] == pdfName("Catalog") {
+				catalog = pdfRef(num)
+			}
+			delete(r.objs, pdfRef(num))
+		}
+	}
+
+	if i := bytes.LastIndex(r.b, []byte("trailer")); i != -1 {
+		l := &pdfLexer{b: r.b, pos: i + len("trailer"), refs: true}
+		if trailer, err := l.value(); err == nil {
+			if trailer, ok := trailer.(pdfDict); ok {
+				if _, ok := r.resolve(trailer["Root"]).(pdfDict); ok {
+					r.trailer = trailer
+					return nil
+				}
+			}
+		}
+	}
+	if catalog == 0 {
+		return fmt.Errorf("document catalog not found")
+	}
+	r.trailer = pdfDict{"Root": catalog}
+	return nil
+}
+
+// readXref reads a cross-reference table or stream at the given offset and returns its trailer dictionary. Entries that were already read by a more recent section take precedence.
+func (r *pdfReader) readXref(offset int) (pdfDict, error) {
+	l := &pdfLexer{b: r.b, pos: offset, refs: true}
+	l.skipSpace()
+	if l.hasPrefix("xref") {
+		l.pos += len("xref")
+		for {
+			l.skipSpace()
+			if l.hasPrefix("trailer") {
+				l.pos += len("trailer")
+				val, err := l.value()
+				if err != nil {
+					return nil, err
+				}
+				trailer, ok := val.(pdfDict)
+				if !ok {
+					return nil, fmt.Errorf("bad trailer")
+				}
+				return trailer, nil
+			}
+
+			start, err1 := l.value()
+			count, err2 := l.value()
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("bad cross-reference table")
+			}
+			n, ok1 := start.(int)
+			m, ok2 := count.(int)
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("bad cross-reference table")
+			}
+			for i := n; i < n+m; i++ {
+				pos, err1 := l.value()
+				_, err2 := l.value()
+				typ, err3 := l.value()
+				if err1 != nil || err2 != nil || err3 != nil {
+					return nil, fmt.Errorf("bad cross-reference table")
+				}
+				if _, ok := r.xref[pdfRef(i)]; !ok && typ == pdfKeyword("n") {
+					if pos, ok := pos.(int); ok {
+						r.xref[pdfRef(i)] = pdfXref{offset: pos}
+					}
+				}
+			}
+		}
+	}
+
+	val, err := r.parseObject(offset)
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := val.(pdfStream)
+	if !ok || stream.dict["Type"] != pdfName("XRef") {
+		return nil, fmt.Errorf("bad cross-reference stream")
+	}
+	b, err := r.decodeStream(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	widths := [3]int{}
+	if W, ok := stream.dict["W"].(pdfArray); ok && len(W) == 3 {
+		for i := range widths {
+			widths[i], _ = W[i].(int)
+		}
+	} else {
+		return nil, fmt.Errorf("bad cross-reference stream")
+	}
+	size, _ := stream.dict["Size"].(int)
+	index := pdfArray{0, size}
+	if array, ok := stream.dict["Index"].(pdfArray); ok {
+		index = array
+	}
+
+	field := func(b []byte, def int) int {
+		if len(b) == 0 {
+			return def
+		}
+		n := 0
+		for _, c := range b {
+			n = n<<8 | int(c)
+		}
+		return n
+	}
+
+	entry := widths[0] + widths[1] + widths[2]
+	for j := 0; j+1 < len(index); j += 2 {
+		start, _ := index[j].(int)
+		count, _ := index[j+1].(int)
+		for i := start; i < start+count && entry <= len(b); i++ {
+			typ := field(b[:widths[0]], 1)
+			f1 := field(b[widths[0]:widths[0]+widths[1]], 0)
+			f2 := field(b[widths[0]+widths[1]:entry], 0)
+			b = b[entry:]
+			if _, ok := r.xref[pdfRef(i)]; ok {
+				continue
+			}
+			if typ == 1 {
+				r.xref[pdfRef(i)] = pdfXref{offset: f1}
+			} else if typ == 2 {
+				r.xref[pdfRef(i)] = pdfXref{offset: f2, stream: pdfRef(f1)}
+			}
+		}
+	}
+	return stream.dict, nil
+}
+
+// parseObject parses the indirect object at the given offset.
+func (r *pdfReader) parseObject(offset int) (interface{}, error) {
+	if offset < 0 || len(r.b) <= offset {
+		return nil, fmt.Errorf("bad object offset %d", offset)
+	}
+	l := &pdfLexer{b: r.b, pos: offset, refs: true}
+	if _, ok := l.readInt(); !ok {
+		return nil, fmt.Errorf("bad object at offset %d", offset)
+	} else if _, ok := l.readInt(); !ok {
+		return nil, fmt.Errorf("bad object at offset %d", offset)
+	} else if keyword, err := l.value(); err != nil || keyword != pdfKeyword("obj") {
+		return nil, fmt.Errorf("bad object at offset %d", offset)
+	}
+
+	val, err := l.value()
+	if err != nil {
+		return nil, err
+	}
+	dict, ok := val.(pdfDict)
+	if !ok {
+		return val, nil
+	}
+	l.skipSpace()
+	if !l.hasPrefix("stream") {
+		return dict, nil
+	}
+
+	l.pos += len("stream")
+	if l.hasPrefix("\r\n") {
+		l.pos += 2
+	} else if l.hasPrefix("\n") || l.hasPrefix("\r") {
+		l.pos++
+	}
+	start := l.pos
+	if length, ok := r.resolve(dict["Length"]).(int); ok && 0 <= length && start+length <= len(r.b) {
+		l.pos = start + length
+		l.skipSpace()
+		if l.hasPrefix("endstream") {
+			return pdfStream{dict: dict, stream: r.b[start : start+length]}, nil
+		}
+	}
+
+	// bad or missing length, look for the end of the stream instead
+	end := bytes.Index(r.b[start:], []byte("endstream"))
+	if end == -1 {
+		return nil, fmt.Errorf("stream at offset %d has no end", offset)
+	}
+	b := r.b[start : start+end]
+	if bytes.HasSuffix(b, []byte("\r\n")) {
+		b = b[:len(b)-2]
+	} else if bytes.HasSuffix(b, []byte("\n")) || bytes.HasSuffix(b, []byte("\r")) {
+		b = b[:len(b)-1]
+	}
+	return pdfStream{dict: dict, stream: b}, nil
+}
+
+func (l *pdfLexer) readInt() (int, bool) {
+	l.skipSpace()
+	i, err := strconv.Atoi(string(l.regular()))
+	return i, err == nil
+}
+
+// getObject returns the indirect object, or nil if it doesn't exist.
+func (r *pdfReader) getObject(ref pdfRef) (interface{}, error) {
+	if val, ok := r.objs[ref]; ok {
+		return val, nil
+	}
+	xref, ok := r.xref[ref]
+	if !ok {
+		return nil, nil
+	}
+	r.objs[ref] = nil // guard against cycles
+
+	var val interface{}
+	var err error
+	if xref.stream == 0 {
+		val, err = r.parseObject(xref.offset)
+	} else {
+		val, err = r.parseStreamObject(xref.stream, ref)
+	}
+	if err != nil {
+		delete(r.objs, ref)
+		return nil, err
+	}
+	r.objs[ref] = val
+	return val, nil
+}
+
+// parseStreamObject parses an object that is stored in an object stream.
+func (r *pdfReader) parseStreamObject(stmRef, ref pdfRef) (interface{}, error) {
+	b, ok := r.objStms[stmRef]
+	if !ok {
+		val, err := r.getObject(stmRef)
+		if err != nil {
+			return nil, err
+		}
+		stream, ok := val.(pdfStream)
+		if !ok {
+			return nil, fmt.Errorf("bad object stream %d", stmRef)
+		}
+		if b, err = r.decodeStream(stream); err != nil {
+			return nil, err
+		}
+		first, _ := stream.dict["First"].(int)
+		if first < 0 || len(b) < first {
+			return nil, fmt.Errorf("bad object stream %d", stmRef)
+		}
+		r.objStms[stmRef] = b
+
+		// the stream starts with pairs of object numbers and offsets relative to First
+		n, _ := stream.dict["N"].(int)
+		l := &pdfLexer{b: b[:first]}
+		for i := 0; i < n; i++ {
+			num, ok1 := l.readInt()
+			offset, ok2 := l.readInt()
+			if !ok1 || !ok2 {
+				break
+			}
+			if xref, ok := r.xref[pdfRef(num)]; ok && xref.stream == stmRef {
+				r.xref[pdfRef(num)] = pdfXref{offset: first + offset, stream: stmRef}
+			}
+		}
+	}
+
+	offset := r.xref[ref].offset
+	if offset < 0 || len(b) <= offset {
+		return nil, fmt.Errorf("bad object %d in object stream %d", ref, stmRef)
+	}
+	l := &pdfLexer{b: b, pos: offset, refs: true}
+	return l.value()
+}
+
+// resolve returns the object that is referenced if val is an indirect reference, or val otherwise. Unresolvable references resolve to nil, as per the specification.
+func (r *pdfReader) resolve(val interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		ref, ok := val.(pdfRef)
+		if !ok {
+			return val
+		}
+		val, _ = r.getObject(ref)
+	}
+	return nil
+}
+
+// decodeStream returns the decoded stream data. Only the Flate, ASCIIHex and ASCII85 filters are supported.
+func (r *pdfReader) decodeStream(stream pdfStream) ([]byte, error) {
+	filters := pdfArray{}
+	params := pdfArray{}
+	if filter, ok := r.resolve(stream.dict["Filter"]).(pdfArray); ok {
+		filters = filter
+	} else if filter, ok := r.resolve(stream.dict["Filter"]).(pdfName); ok {
+		filters = pdfArray{filter}
+	}
+	if param, ok := r.resolve(stream.dict["DecodeParms"]).(pdfArray); ok {
+		params = param
+	} else if param, ok := r.resolve(stream.dict["DecodeParms"]).(pdfDict); ok {
+		params = pdfArray{param}
+	}
+
+	b := stream.stream
+	for i, filter := range filters {
+		var param pdfDict
+		if i < len(params) {
+			param, _ = r.resolve(params[i]).(pdfDict)
+		}
+
+		var err error
+		switch filter {
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			var zr io.ReadCloser
+			if zr, err = zlib.NewReader(bytes.NewReader(b)); err != nil {
+				return nil, err
+			}
+			b, err = ioutil.ReadAll(zr)
+			if err != nil && err != io.ErrUnexpectedEOF && len(b) == 0 {
+				return nil, err
+			}
+			if b, err = r.unpredict(b, param); err != nil {
+				return nil, err
+			}
+		case pdfName("ASCIIHexDecode"), pdfName("AHx"):
+			s := make([]byte, 0, len(b))
+			for _, c := range b {
+				if c == '>' {
+					break
+				} else if !isWhitespace(c) {
+					s = append(s, c)
+				}
+			}
+			if len(s)%2 == 1 {
+				s = append(s, '0')
+			}
+			if b, err = hex.DecodeString(string(s)); err != nil {
+				return nil, err
+			}
+		case pdfName("ASCII85Decode"), pdfName("A85"):
+			if i := bytes.Index(b, []byte("~>")); i != -1 {
+				b = b[:i]
+			}
+			b = bytes.TrimPrefix(bytes.TrimSpace(b), []byte("<~"))
+			dst := make([]byte, 4*len(b))
+			n, _, err := ascii85.Decode(dst, b, true)
+			if err != nil {
+				return nil, err
+			}
+			b = dst[:n]
+		default:
+			return nil, fmt.Errorf("unsupported stream filter %v", filter)
+		}
+	}
+	return b, nil
+}
+
+// unpredict reverses the PNG predictors of Flate encoded data.
+func (r *pdfReader) unpredict(b []byte, param pdfDict) ([]byte, error) {
+	predictor, _ := param["Predictor"].(int)
+	if predictor < 10 {
+		if predictor == 2 {
+			return nil, fmt.Errorf("unsupported TIFF predictor")
+		}
+		return b, nil
+	}
+
+	colors, bpc, columns := 1, 8, 1
+	if v, ok := param["Colors"].(int); ok && 0 < v {
+		colors = v
+	}
+	if v, ok := param["BitsPerComponent"].(int); ok && 0 < v {
+		bpc = v
+	}
+	if v, ok := param["Columns"].(int); ok && 0 < v {
+		columns = v
+	}
+	bpp := (colors*bpc + 7) / 8
+	rowLen := (colors*bpc*columns + 7) / 8
+
+	dst := make([]byte, 0, len(b))
+	prev := make([]byte, rowLen)
+	for 0 < len(b) {
+		if len(b) < rowLen+1 {
+			break
+		}
+		filter, row := b[0], b[1:rowLen+1]
+		b = b[rowLen+1:]
+		for i := range row {
+			var left, upLeft byte
+			if bpp <= i {
+				left = row[i-bpp]
+				upLeft = prev[i-bpp]
+			}
+			up := prev[i]
+			switch filter {
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				p := int(left) + int(up) - int(upLeft)
+				pa, pb, pc := abs(p-int(left)), abs(p-int(up)), abs(p-int(upLeft))
+				if pa <= pb && pa <= pc {
+					row[i] += left
+				} else if pb <= pc {
+					row[i] += up
+				} else {
+					row[i] += upLeft
+				}
+			}
+		}
+		dst = append(dst, row...)
+		prev = row
+	}
+	return dst, nil
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// pages returns the page dictionaries in order, with the inheritable attributes copied from their ancestors.
+func (r *pdfReader) pages() ([]pdfDict, error) {
+	catalog, ok := r.resolve(r.trailer["Root"]).(pdfDict)
+	if !ok {
+		return nil, fmt.Errorf("document catalog not found")
+	}
+	root, ok := r.resolve(catalog["Pages"]).(pdfDict)
+	if !ok {
+		return nil, fmt.Errorf("page tree not found")
+	}
+
+	// every node may be visited only once, otherwise a malformed page tree with cycles or shared kids expands exponentially
+	visited := map[pdfRef]bool{}
+	if ref, ok := catalog["Pages"].(pdfRef); ok {
+		visited[ref] = true
+	}
+
+	pages := []pdfDict{}
+	var walk func(pdfDict, pdfDict, int) error
+	walk = func(node, inherited pdfDict, depth int) error {
+		if 64 < depth {
+			return nil
+		}
+		attrs := pdfDict{}
+		for key, val := range inherited {
+			attrs[key] = val
+		}
+		for _, key := range []pdfName{"Resources", "MediaBox", "CropBox", "Rotate"} {
+			if val, ok := node[key]; ok {
+				attrs[key] = val
+			}
+		}
+
+		if node["Type"] == pdfName("Page") {
+			page := pdfDict{}
+			for key, val := range node {
+				page[key] = val
+			}
+			for key, val := range attrs {
+				page[key] = val
+			}
+			pages = append(pages, page)
+			return nil
+		}
+		kids, _ := r.resolve(node["Kids"]).(pdfArray)
+		for _, kid := range kids {
+			if ref, ok := kid.(pdfRef); ok {
+				if visited[ref] {
+					return fmt.Errorf("page tree: object %d is referenced more than once", ref)
+				}
+				visited[ref] = true
+			}
+			if kid, ok := r.resolve(kid).(pdfDict); ok {
+				if err := walk(kid, attrs, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(root, pdfDict{}, 0); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
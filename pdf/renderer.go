@@ -63,8 +63,51 @@ func (r *PDF) Size() (float64, float64) {
 }
 
 func (r *PDF) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix) {
-	fill := style.FillColor.A != 0
-	stroke := style.StrokeColor.A != 0 && 0.0 < style.StrokeWidth
+	_, fillColorPaint := style.FillPaint.(canvas.ColorPaint)
+	_, strokeColorPaint := style.StrokePaint.(canvas.ColorPaint)
+	if style.FillPaint != nil && !fillColorPaint || style.StrokePaint != nil && !strokeColorPaint {
+		if !paintSupported(style.FillPaint) || !paintSupported(style.StrokePaint) {
+			canvas.RenderPaintAsImage(r, path, style, m)
+			return
+		}
+
+		// draw gradients as shadings clipped by the path or its stroke, and patterns as tiling patterns
+		if style.FillPaint != nil && !fillColorPaint {
+			if style.HasFill() {
+				r.drawPaint(path.Transform(m).ToPDF(), style.FillRule, style.FillPaint, m)
+			}
+			style.FillColor = canvas.Transparent
+			style.FillPaint = nil
+		}
+		if style.StrokePaint != nil && !strokeColorPaint {
+			strokePaint := style.StrokePaint
+			stroke := style.HasStroke()
+			style.StrokeColor = canvas.Transparent
+			style.StrokePaint = nil
+			r.RenderPath(path, style, m)
+
+			if stroke {
+				if 0 < len(style.Dashes) {
+					path = path.Dash(style.DashOffset, style.Dashes...)
+				}
+				path = path.Stroke(style.StrokeWidth, style.StrokeCapper, style.StrokeJoiner)
+				r.drawPaint(path.Transform(m).ToPDF(), canvas.NonZero, strokePaint, m)
+			}
+			return
+		}
+	}
+
+	// color paints hold CMYK colors that are written in the DeviceCMYK color space
+	var fillColor, strokeColor color.Color = style.FillColor, style.StrokeColor
+	if p, ok := style.FillPaint.(canvas.ColorPaint); ok {
+		fillColor = p.C
+	}
+	if p, ok := style.StrokePaint.(canvas.ColorPaint); ok {
+		strokeColor = p.C
+	}
+
+	fill := style.HasFill()
+	stroke := style.HasStroke()
 	differentAlpha := fill && stroke && style.FillColor.A != style.StrokeColor.A
 
 	// PDFs don't support the arcs joiner, miter joiner (not clipped), or miter joiner (clipped) with non-bevel fallback
@@ -94,7 +137,7 @@ func (r *PDF) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix)
 
 	if !stroke || !strokeUnsupported {
 		if fill && !stroke {
-			r.w.SetFillColor(style.FillColor)
+			r.w.SetFillColor(fillColor)
 			r.w.Write([]byte(" "))
 			r.w.Write([]byte(data))
 			r.w.Write([]byte(" f"))
@@ -102,7 +145,7 @@ func (r *PDF) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix)
 				r.w.Write([]byte("*"))
 			}
 		} else if !fill && stroke {
-			r.w.SetStrokeColor(style.StrokeColor)
+			r.w.SetStrokeColor(strokeColor)
 			r.w.SetLineWidth(style.StrokeWidth)
 			r.w.SetLineCap(style.StrokeCapper)
 			r.w.SetLineJoin(style.StrokeJoiner)
@@ -119,8 +162,8 @@ func (r *PDF) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix)
 			}
 		} else if fill && stroke {
 			if !differentAlpha {
-				r.w.SetFillColor(style.FillColor)
-				r.w.SetStrokeColor(style.StrokeColor)
+				r.w.SetFillColor(fillColor)
+				r.w.SetStrokeColor(strokeColor)
 				r.w.SetLineWidth(style.StrokeWidth)
 				r.w.SetLineCap(style.StrokeCapper)
 				r.w.SetLineJoin(style.StrokeJoiner)
@@ -136,7 +179,7 @@ func (r *PDF) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix)
 					r.w.Write([]byte("*"))
 				}
 			} else {
-				r.w.SetFillColor(style.FillColor)
+				r.w.SetFillColor(fillColor)
 				r.w.Write([]byte(" "))
 				r.w.Write([]byte(data))
 				r.w.Write([]byte(" f"))
@@ -144,7 +187,7 @@ func (r *PDF) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix)
 					r.w.Write([]byte("*"))
 				}
 
-				r.w.SetStrokeColor(style.StrokeColor)
+				r.w.SetStrokeColor(strokeColor)
 				r.w.SetLineWidth(style.StrokeWidth)
 				r.w.SetLineCap(style.StrokeCapper)
 				r.w.SetLineJoin(style.StrokeJoiner)
@@ -164,7 +207,7 @@ func (r *PDF) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix)
 	} else {
 		// stroke && strokeUnsupported
 		if fill {
-			r.w.SetFillColor(style.FillColor)
+			r.w.SetFillColor(fillColor)
 			r.w.Write([]byte(" "))
 			r.w.Write([]byte(data))
 			r.w.Write([]byte(" f"))
@@ -179,7 +222,7 @@ func (r *PDF) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix)
 		}
 		path = path.Stroke(style.StrokeWidth, style.StrokeCapper, style.StrokeJoiner)
 
-		r.w.SetFillColor(style.StrokeColor)
+		r.w.SetFillColor(strokeColor)
 		r.w.Write([]byte(" "))
 		r.w.Write([]byte(path.ToPDF()))
 		r.w.Write([]byte(" f"))
@@ -189,11 +232,24 @@ func (r *PDF) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix)
 	}
 }
 
+// drawPaint fills the path data with a gradient or pattern, where m is the transformation of the path.
+func (r *PDF) drawPaint(data string, fillRule canvas.FillRule, paint canvas.Paint, m canvas.Matrix) {
+	if pattern, ok := paint.(*canvas.Pattern); ok {
+		r.w.DrawPattern(data, fillRule, pattern, m, r.imgEnc)
+	} else {
+		r.w.DrawShading(data, fillRule, paint, m)
+	}
+}
+
 func (r *PDF) RenderText(text *canvas.Text, m canvas.Matrix) {
 	r.w.StartTextObject()
 
 	text.WalkSpans(func(y, dx float64, span canvas.TextSpan) {
-		r.w.SetFillColor(span.Face.Color)
+		if p, ok := span.Face.Paint.(canvas.ColorPaint); ok {
+			r.w.SetFillColor(p.C)
+		} else {
+			r.w.SetFillColor(span.Face.Color)
+		}
 		r.w.SetFont(span.Face.Font, span.Face.Size*span.Face.Scale)
 		r.w.SetTextPosition(m.Translate(dx, y).Shear(span.Face.FauxItalic, 0.0))
 		r.w.SetTextCharSpace(span.GlyphSpacing)
@@ -221,6 +277,10 @@ func (r *PDF) RenderText(text *canvas.Text, m canvas.Matrix) {
 }
 
 func (r *PDF) RenderImage(img image.Image, m canvas.Matrix) {
+	if t, ok := img.(*Template); ok {
+		r.w.DrawTemplate(t, m)
+		return
+	}
 	r.w.DrawImage(img, r.imgEnc, m)
 }
 
@@ -231,19 +291,24 @@ type pdfWriter struct {
 	pos        int
 	objOffsets []int
 
-	fonts    map[*canvas.Font]pdfRef
-	pages    []*pdfPageWriter
-	compress bool
-	title    string
-	subject  string
-	keywords string
-	author   string
+	fonts     map[*canvas.Font]pdfRef
+	templates map[*Template]pdfRef
+	imports   map[*pdfReader]map[pdfRef]pdfRef // references of imported objects
+	patterns  []*canvas.Pattern                // patterns whose tiles are being written
+	pages     []*pdfPageWriter
+	compress  bool
+	title     string
+	subject   string
+	keywords  string
+	author    string
 }
 
 func newPDFWriter(writer io.Writer) *pdfWriter {
 	w := &pdfWriter{
 		w:          writer,
 		fonts:      map[*canvas.Font]pdfRef{},
+		templates:  map[*Template]pdfRef{},
+		imports:    map[*pdfReader]map[pdfRef]pdfRef{},
 		objOffsets: []int{0, 0, 0}, // catalog, metadata, page tree
 	}
 
@@ -306,6 +371,8 @@ const (
 
 func (w *pdfWriter) writeVal(i interface{}) {
 	switch v := i.(type) {
+	case nil:
+		w.write("null")
 	case bool:
 		if v {
 			w.write("true")
@@ -320,10 +387,13 @@ func (w *pdfWriter) writeVal(i interface{}) {
 		v = strings.Replace(v, `\`, `\\`, -1)
 		v = strings.Replace(v, `(`, `\(`, -1)
 		v = strings.Replace(v, `)`, `\)`, -1)
+		v = strings.Replace(v, "\r", `\r`, -1)
 		w.write("(%v)", v)
 	case pdfRef:
 		w.write("%v 0 R", v)
-	case pdfName, pdfFilter:
+	case pdfName:
+		w.write("/%v", escapeName(string(v)))
+	case pdfFilter:
 		w.write("/%v", v)
 	case pdfArray:
 		w.write("[")
@@ -397,17 +467,38 @@ func (w *pdfWriter) writeVal(i interface{}) {
 		w.write(" stream\n")
 		w.writeBytes(b)
 		w.write("\nendstream")
+	case pdfRawStream:
+		dict := pdfDict{}
+		for key, val := range v.dict {
+			dict[key] = val
+		}
+		dict["Length"] = len(v.stream)
+		w.writeVal(dict)
+		w.write(" stream\n")
+		w.writeBytes(v.stream)
+		w.write("\nendstream")
 	default:
 		panic(fmt.Sprintf("unknown PDF type %T", i))
 	}
 }
 
 func (w *pdfWriter) writeObject(val interface{}) pdfRef {
-	w.objOffsets = append(w.objOffsets, w.pos)
-	w.write("%v 0 obj\n", len(w.objOffsets))
+	ref := w.reserveObject()
+	w.writeReservedObject(ref, val)
+	return ref
+}
+
+// reserveObject returns the reference of an object that is written later by writeReservedObject, which allows objects to reference each other.
+func (w *pdfWriter) reserveObject() pdfRef {
+	w.objOffsets = append(w.objOffsets, 0)
+	return pdfRef(len(w.objOffsets))
+}
+
+func (w *pdfWriter) writeReservedObject(ref pdfRef, val interface{}) {
+	w.objOffsets[ref-1] = w.pos
+	w.write("%v 0 obj\n", ref)
 	w.writeVal(val)
 	w.write("\nendobj\n")
-	return pdfRef(len(w.objOffsets))
 }
 
 func (w *pdfWriter) getFont(font *canvas.Font) pdfRef {
@@ -592,11 +683,12 @@ type pdfPageWriter struct {
 	pdf           *pdfWriter
 	width, height float64
 	resources     pdfDict
+	base          canvas.Matrix // maps millimeters to the default coordinate space of the content stream
 
 	graphicsStates map[float64]pdfName
 	alpha          float64
-	fillColor      color.RGBA
-	strokeColor    color.RGBA
+	fillColor      color.Color
+	strokeColor    color.Color
 	lineWidth      float64
 	lineCap        int
 	lineJoin       int
@@ -611,13 +703,25 @@ type pdfPageWriter struct {
 }
 
 func (w *pdfWriter) NewPage(width, height float64) *pdfPageWriter {
+	page := w.newPage(width, height)
+	w.pages = append(w.pages, page)
+
+	m := canvas.Identity.Scale(ptPerMm, ptPerMm)
+	fmt.Fprintf(page, " %v %v %v %v %v %v cm", dec(m[0][0]), dec(m[1][0]), dec(m[0][1]), dec(m[1][1]), dec(m[0][2]), dec(m[1][2]))
+	page.base = m
+	return page
+}
+
+// newPage returns a content stream writer with its own resources and graphics state, which is not added to the pages of the document.
+func (w *pdfWriter) newPage(width, height float64) *pdfPageWriter {
 	// for defaults see https://help.adobe.com/pdfl_sdk/15/PDFL_SDK_HTMLHelp/PDFL_SDK_HTMLHelp/API_References/PDFL_API_Reference/PDFEdit_Layer/General.html#_t_PDEGraphicState
-	page := &pdfPageWriter{
+	return &pdfPageWriter{
 		Buffer:         &bytes.Buffer{},
 		pdf:            w,
 		width:          width,
 		height:         height,
 		resources:      pdfDict{},
+		base:           canvas.Identity,
 		graphicsStates: map[float64]pdfName{},
 		alpha:          1.0,
 		fillColor:      canvas.Black,
@@ -634,11 +738,6 @@ func (w *pdfWriter) NewPage(width, height float64) *pdfPageWriter {
 		textCharSpace:  0.0,
 		textRenderMode: 0,
 	}
-	w.pages = append(w.pages, page)
-
-	m := canvas.Identity.Scale(ptPerMm, ptPerMm)
-	fmt.Fprintf(page, " %v %v %v %v %v %v cm", dec(m[0][0]), dec(m[1][0]), dec(m[0][1]), dec(m[1][1]), dec(m[0][2]), dec(m[1][2]))
-	return page
 }
 
 func (w *pdfPageWriter) writePage(parent pdfRef) pdfRef {
@@ -677,28 +776,48 @@ func (w *pdfPageWriter) SetAlpha(alpha float64) {
 	}
 }
 
-func (w *pdfPageWriter) SetFillColor(fillColor color.RGBA) {
-	a := float64(fillColor.A) / 255.0
-	if fillColor != w.fillColor {
-		if fillColor.R == fillColor.G && fillColor.R == fillColor.B {
-			fmt.Fprintf(w, " %v g", dec(float64(fillColor.R)/255.0/a))
+func (w *pdfPageWriter) SetFillColor(fillColor color.Color) {
+	if cmyk, ok := fillColor.(color.CMYK); ok {
+		if cmyk != w.fillColor {
+			fmt.Fprintf(w, " %v %v %v %v k", dec(float64(cmyk.C)/255.0), dec(float64(cmyk.M)/255.0), dec(float64(cmyk.Y)/255.0), dec(float64(cmyk.K)/255.0))
+			w.fillColor = cmyk
+		}
+		w.SetAlpha(1.0)
+		return
+	}
+
+	col := color.RGBAModel.Convert(fillColor).(color.RGBA)
+	a := float64(col.A) / 255.0
+	if col != w.fillColor {
+		if col.R == col.G && col.R == col.B {
+			fmt.Fprintf(w, " %v g", dec(float64(col.R)/255.0/a))
 		} else {
-			fmt.Fprintf(w, " %v %v %v rg", dec(float64(fillColor.R)/255.0/a), dec(float64(fillColor.G)/255.0/a), dec(float64(fillColor.B)/255.0/a))
+			fmt.Fprintf(w, " %v %v %v rg", dec(float64(col.R)/255.0/a), dec(float64(col.G)/255.0/a), dec(float64(col.B)/255.0/a))
 		}
-		w.fillColor = fillColor
+		w.fillColor = col
 	}
 	w.SetAlpha(a)
 }
 
-func (w *pdfPageWriter) SetStrokeColor(strokeColor color.RGBA) {
-	a := float64(strokeColor.A) / 255.0
-	if strokeColor != w.strokeColor {
-		if strokeColor.R == strokeColor.G && strokeColor.R == strokeColor.B {
-			fmt.Fprintf(w, " %v G", dec(float64(strokeColor.R)/255.0/a))
+func (w *pdfPageWriter) SetStrokeColor(strokeColor color.Color) {
+	if cmyk, ok := strokeColor.(color.CMYK); ok {
+		if cmyk != w.strokeColor {
+			fmt.Fprintf(w, " %v %v %v %v K", dec(float64(cmyk.C)/255.0), dec(float64(cmyk.M)/255.0), dec(float64(cmyk.Y)/255.0), dec(float64(cmyk.K)/255.0))
+			w.strokeColor = cmyk
+		}
+		w.SetAlpha(1.0)
+		return
+	}
+
+	col := color.RGBAModel.Convert(strokeColor).(color.RGBA)
+	a := float64(col.A) / 255.0
+	if col != w.strokeColor {
+		if col.R == col.G && col.R == col.B {
+			fmt.Fprintf(w, " %v G", dec(float64(col.R)/255.0/a))
 		} else {
-			fmt.Fprintf(w, " %v %v %v RG", dec(float64(strokeColor.R)/255.0/a), dec(float64(strokeColor.G)/255.0/a), dec(float64(strokeColor.B)/255.0/a))
+			fmt.Fprintf(w, " %v %v %v RG", dec(float64(col.R)/255.0/a), dec(float64(col.G)/255.0/a), dec(float64(col.B)/255.0/a))
 		}
-		w.strokeColor = strokeColor
+		w.strokeColor = col
 	}
 	w.SetAlpha(a)
 }
@@ -1002,6 +1121,186 @@ func (w *pdfPageWriter) embedImage(img image.Image, enc canvas.ImageEncoding) pd
 	return name
 }
 
+// paintSupported returns true if the paint is a solid color, a pattern, or a linear or radial gradient that can be written natively as a shading, which requires the gradient to be padded and to have the same opacity for all stops.
+func paintSupported(paint canvas.Paint) bool {
+	var gradient *canvas.Gradient
+	switch g := paint.(type) {
+	case nil, canvas.ColorPaint, *canvas.Pattern:
+		return true
+	case *canvas.LinearGradient:
+		gradient = &g.Gradient
+	case *canvas.RadialGradient:
+		gradient = &g.Gradient
+	default:
+		return false
+	}
+	if gradient.Spread != canvas.PadSpread || len(gradient.Stops) == 0 {
+		return false
+	}
+	for _, stop := range gradient.Stops[1:] {
+		if stop.Color.A != gradient.Stops[0].Color.A {
+			return false
+		}
+	}
+	return true
+}
+
+// DrawShading fills the path data with a linear or radial gradient, where m is the transformation of the path.
+func (w *pdfPageWriter) DrawShading(data string, fillRule canvas.FillRule, paint canvas.Paint, m canvas.Matrix) {
+	var gradient *canvas.Gradient
+	shading := pdfDict{
+		"ColorSpace": pdfName("DeviceRGB"),
+		"Extend":     pdfArray{true, true},
+	}
+	switch g := paint.(type) {
+	case *canvas.LinearGradient:
+		gradient = &g.Gradient
+		shading["ShadingType"] = 2
+		shading["Coords"] = pdfArray{g.Start.X, g.Start.Y, g.End.X, g.End.Y}
+	case *canvas.RadialGradient:
+		gradient = &g.Gradient
+		shading["ShadingType"] = 3
+		shading["Coords"] = pdfArray{g.C0.X, g.C0.Y, g.R0, g.C1.X, g.C1.Y, g.R1}
+	}
+
+	// stitch exponential interpolation functions between the stops, which must span the domain from 0 to 1
+	stops := gradient.Stops
+	if 0.0 < stops[0].Offset {
+		first := stops[0]
+		first.Offset = 0.0
+		stops = append([]canvas.Stop{first}, stops...)
+	}
+	if stops[len(stops)-1].Offset < 1.0 {
+		last := stops[len(stops)-1]
+		last.Offset = 1.0
+		stops = append(stops, last)
+	}
+
+	// use the CMYK color space when all stops have CMYK colors
+	isCMYK := true
+	for _, stop := range stops {
+		if p, ok := stop.Paint.(canvas.ColorPaint); !ok {
+			isCMYK = false
+			break
+		} else if _, ok := p.C.(color.CMYK); !ok {
+			isCMYK = false
+			break
+		}
+	}
+	components := func(stop canvas.Stop) pdfArray {
+		if isCMYK {
+			cmyk := stop.Paint.(canvas.ColorPaint).C.(color.CMYK)
+			return pdfArray{float64(cmyk.C) / 255.0, float64(cmyk.M) / 255.0, float64(cmyk.Y) / 255.0, float64(cmyk.K) / 255.0}
+		}
+		col := stop.Color
+		a := float64(col.A) / 255.0
+		if a == 0.0 {
+			return pdfArray{0.0, 0.0, 0.0}
+		}
+		return pdfArray{float64(col.R) / 255.0 / a, float64(col.G) / 255.0 / a, float64(col.B) / 255.0 / a}
+	}
+	if isCMYK {
+		shading["ColorSpace"] = pdfName("DeviceCMYK")
+	}
+	functions := pdfArray{}
+	bounds := pdfArray{}
+	encode := pdfArray{}
+	for i := 1; i < len(stops); i++ {
+		functions = append(functions, pdfDict{
+			"FunctionType": 2,
+			"Domain":       pdfArray{0.0, 1.0},
+			"C0":           components(stops[i-1]),
+			"C1":           components(stops[i]),
+			"N":            1.0,
+		})
+		if 1 < i {
+			bounds = append(bounds, stops[i-1].Offset)
+		}
+		encode = append(encode, 0.0, 1.0)
+	}
+	if len(functions) == 1 {
+		shading["Function"] = functions[0]
+	} else {
+		shading["Function"] = pdfDict{
+			"FunctionType": 3,
+			"Domain":       pdfArray{0.0, 1.0},
+			"Functions":    functions,
+			"Bounds":       bounds,
+			"Encode":       encode,
+		}
+	}
+
+	if _, ok := w.resources["Shading"]; !ok {
+		w.resources["Shading"] = pdfDict{}
+	}
+	name := pdfName(fmt.Sprintf("Sh%d", len(w.resources["Shading"].(pdfDict))))
+	w.resources["Shading"].(pdfDict)[name] = w.pdf.writeObject(shading)
+
+	w.SetAlpha(float64(gradient.Stops[0].Color.A) / 255.0)
+	fmt.Fprintf(w, " q %v W", data)
+	if fillRule == canvas.EvenOdd {
+		fmt.Fprintf(w, "*")
+	}
+	m = m.Mul(gradient.M)
+	fmt.Fprintf(w, " n %v %v %v %v %v %v cm /%v sh Q", dec(m[0][0]), dec(m[1][0]), dec(m[0][1]), dec(m[1][1]), dec(m[0][2]), dec(m[1][2]), name)
+}
+
+// DrawPattern fills the path data with a tiling pattern, where m is the transformation of the path. The tile is rendered into the content stream of the pattern, a pattern that is used in its own tile is not drawn.
+func (w *pdfPageWriter) DrawPattern(data string, fillRule canvas.FillRule, pattern *canvas.Pattern, m canvas.Matrix, enc canvas.ImageEncoding) {
+	for _, p := range w.pdf.patterns {
+		if p == pattern {
+			return
+		}
+	}
+
+	tile := w.pdf.newPage(pattern.Tile.W, pattern.Tile.H)
+	w.pdf.patterns = append(w.pdf.patterns, pattern)
+	pattern.Tile.Render(&PDF{
+		w:      tile,
+		width:  pattern.Tile.W,
+		height: pattern.Tile.H,
+		imgEnc: enc,
+	})
+	w.pdf.patterns = w.pdf.patterns[:len(w.pdf.patterns)-1]
+
+	// the pattern matrix maps to the default coordinate space of the parent content stream, which is in points for pages and in millimeters for the tiles of other patterns
+	b := tile.Bytes()
+	if 0 < len(b) && b[0] == ' ' {
+		b = b[1:]
+	}
+	m = w.base.Mul(m).Mul(pattern.M)
+	stream := pdfStream{
+		dict: pdfDict{
+			"Type":        pdfName("Pattern"),
+			"PatternType": 1,
+			"PaintType":   1,
+			"TilingType":  1,
+			"BBox":        pdfArray{0.0, 0.0, pattern.Tile.W, pattern.Tile.H},
+			"XStep":       pattern.Tile.W + pattern.Spacing.X,
+			"YStep":       pattern.Tile.H + pattern.Spacing.Y,
+			"Matrix":      pdfArray{m[0][0], m[1][0], m[0][1], m[1][1], m[0][2], m[1][2]},
+			"Resources":   tile.resources,
+		},
+		stream: b,
+	}
+	if w.pdf.compress {
+		stream.dict["Filter"] = pdfFilterFlate
+	}
+
+	if _, ok := w.resources["Pattern"]; !ok {
+		w.resources["Pattern"] = pdfDict{}
+	}
+	name := pdfName(fmt.Sprintf("P%d", len(w.resources["Pattern"].(pdfDict))))
+	w.resources["Pattern"].(pdfDict)[name] = w.pdf.writeObject(stream)
+
+	w.SetAlpha(1.0)
+	fmt.Fprintf(w, " q /Pattern cs /%v scn %v f", name, data)
+	if fillRule == canvas.EvenOdd {
+		fmt.Fprintf(w, "*")
+	}
+	fmt.Fprintf(w, " Q")
+}
+
 func (w *pdfPageWriter) getOpacityGS(a float64) pdfName {
 	if name, ok := w.graphicsStates[a]; ok {
 		return name
@@ -3,6 +3,8 @@ package pdf
 import (
 	"bytes"
 	"image"
+	"image/color"
+	"math"
 	"strings"
 	"testing"
 
@@ -106,3 +108,223 @@ func TestPDFMultipage(t *testing.T) {
 	nbPages := strings.Count(out, "/Type /Page ")
 	test.That(t, nbPages == 2, "expected 2 pages, got", nbPages)
 }
+
+func TestPDFTemplate(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		c := canvas.New(100.0, 50.0)
+		ctx := canvas.NewContext(c)
+		ctx.SetFillColor(canvas.Red)
+		ctx.DrawPath(0.0, 0.0, canvas.Rectangle(50.0, 50.0))
+
+		buf := &bytes.Buffer{}
+		pdf := New(buf, c.W, c.H)
+		pdf.SetCompression(compress)
+		c.Render(pdf)
+		pdf.NewPage(20.0, 20.0)
+		test.Error(t, pdf.Close())
+
+		templates, err := ReadTemplates(buf)
+		test.Error(t, err)
+		test.T(t, len(templates), 2)
+		w, h := templates[0].Size()
+		test.That(t, math.Abs(w-100.0) < 1e-3 && math.Abs(h-50.0) < 1e-3, "bad template size", w, h)
+
+		// rasterized fallback
+		tpl := templates[0]
+		tpl.Resolution = 1.0
+		test.T(t, tpl.Bounds(), image.Rect(0, 0, 100, 50))
+		test.T(t, tpl.At(25, 25), color.Color(canvas.Red))
+		test.T(t, tpl.At(75, 25), color.Color(canvas.Transparent))
+		img := tpl.Image()
+		test.That(t, tpl.Image() == img, "expected the page to be rasterized once")
+		tpl.Resolution = 0.5
+		test.T(t, tpl.Image().Bounds(), image.Rect(0, 0, 50, 25))
+		tpl.Resolution = 1.0
+
+		// embedded as form XObject, only once
+		c = canvas.New(210.0, 297.0)
+		ctx = canvas.NewContext(c)
+		tpl.Draw(ctx, 0.0, 0.0)
+		tpl.Draw(ctx, 0.0, 100.0)
+		buf = &bytes.Buffer{}
+		test.Error(t, Writer(buf, c))
+		out := buf.String()
+		test.That(t, strings.Count(out, "/Subtype /Form") == 1, "expected one form XObject")
+		test.That(t, strings.Contains(out, "/Fm0 Do Q q .35277778 0 0 .35277778 0 100 cm /Fm0 Do Q"), "expected form XObject to be drawn twice")
+
+		templates, err = ReadTemplates(buf)
+		test.Error(t, err)
+		test.T(t, len(templates), 1)
+	}
+
+	_, err := ReadTemplates(strings.NewReader("not a pdf"))
+	test.That(t, err != nil)
+}
+
+func TestPDFTemplateSize(t *testing.T) {
+	// pages larger than 200 inches are rejected
+	buf := &bytes.Buffer{}
+	test.Error(t, New(buf, 10000.0, 100.0).Close())
+	_, err := ReadTemplates(buf)
+	test.That(t, err != nil && strings.Contains(err.Error(), "page size"), err)
+
+	// large pages are rasterized at a lower resolution
+	buf = &bytes.Buffer{}
+	test.Error(t, New(buf, 5000.0, 5000.0).Close())
+	templates, err := ReadTemplates(buf)
+	test.Error(t, err)
+	tpl := templates[0]
+	tpl.Resolution = 10.0
+	size := tpl.Bounds().Size()
+	test.That(t, 0 < size.X && size.X*size.Y <= maxTemplatePixels+size.X+size.Y, "bad rasterized size", size) // up to rounding
+}
+
+func TestPDFTemplatePageTree(t *testing.T) {
+	// kids that point back at a node or at the same node twice are rejected
+	_, err := ReadTemplates(strings.NewReader("%PDF-1.7\n1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n2 0 obj << /Type /Pages /Kids [3 0 R] >> endobj\n3 0 obj << /Type /Pages /Kids [2 0 R] >> endobj\n"))
+	test.That(t, err != nil && strings.Contains(err.Error(), "page tree"), err)
+	_, err = ReadTemplates(strings.NewReader("%PDF-1.7\n1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n2 0 obj << /Type /Pages /Kids [3 0 R 3 0 R] >> endobj\n3 0 obj << /Type /Page >> endobj\n"))
+	test.That(t, err != nil && strings.Contains(err.Error(), "page tree"), err)
+
+	templates, err := ReadTemplates(strings.NewReader("%PDF-1.7\n1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n2 0 obj << /Type /Pages /Kids [3 0 R 4 0 R] >> endobj\n3 0 obj << /Type /Page >> endobj\n4 0 obj << /Type /Page >> endobj\n"))
+	test.Error(t, err)
+	test.T(t, len(templates), 2)
+}
+
+func TestPDFReader(t *testing.T) {
+	l := &pdfLexer{b: []byte(`<< /Name#20A (str\(\)\101) /Array [1 2 0 R -3.5 <414> true null] >>`), refs: true}
+	val, err := l.value()
+	test.Error(t, err)
+	test.T(t, val, pdfDict{"Name A": "str()A", "Array": pdfArray{1, pdfRef(2), -3.5, "A@", true, nil}})
+
+	buf := &bytes.Buffer{}
+	newPDFWriter(buf).writeVal(pdfName("Name A"))
+	test.String(t, buf.String(), "%PDF-1.7\n/Name#20A")
+}
+
+func TestPDFGradient(t *testing.T) {
+	radial := canvas.NewRadialGradient(canvas.Point{X: 5.0, Y: 5.0}, 0.0, canvas.Point{X: 5.0, Y: 5.0}, 5.0)
+	radial.AddColorStop(0.0, canvas.Red)
+	radial.AddColorStop(0.5, canvas.Green)
+	radial.AddColorStop(1.0, canvas.Blue)
+
+	buf := &bytes.Buffer{}
+	pdf := New(buf, 10.0, 10.0)
+	pdf.SetCompression(false)
+	style := canvas.DefaultStyle
+	style.FillColor = radial.Color()
+	style.FillPaint = radial
+	pdf.RenderPath(canvas.Rectangle(10.0, 10.0), style, canvas.Identity)
+	test.String(t, pdf.w.String(), " 2.8346457 0 0 2.8346457 0 0 cm q 0 0 m 10 0 l 10 10 l 0 10 l h W n 1 0 0 1 0 0 cm /Sh0 sh Q")
+	test.Error(t, pdf.Close())
+
+	out := buf.String()
+	test.That(t, strings.Contains(out, "/ShadingType 3"), "expected radial shading")
+	test.That(t, strings.Contains(out, "/FunctionType 3"), "expected stitching function")
+	test.That(t, strings.Contains(out, "/Bounds [.5]"), "expected bounds of stitching function")
+
+	// repeating gradients are rasterized
+	radial.Spread = canvas.RepeatSpread
+	buf.Reset()
+	pdf = New(buf, 10.0, 10.0)
+	pdf.RenderPath(canvas.Rectangle(10.0, 10.0), style, canvas.Identity)
+	test.That(t, strings.Contains(pdf.w.String(), "/Im0 Do"), "expected repeating gradient to be rasterized")
+}
+
+func TestPDFPattern(t *testing.T) {
+	pattern := canvas.NewCheckerboardPattern(canvas.Black, canvas.White, 1.0)
+
+	buf := &bytes.Buffer{}
+	pdf := New(buf, 10.0, 10.0)
+	pdf.SetCompression(false)
+	style := canvas.DefaultStyle
+	style.FillColor = pattern.Color()
+	style.FillPaint = pattern
+	pdf.RenderPath(canvas.Rectangle(10.0, 10.0), style, canvas.Identity)
+	test.String(t, pdf.w.String(), " 2.8346457 0 0 2.8346457 0 0 cm q /Pattern cs /P0 scn 0 0 m 10 0 l 10 10 l 0 10 l h f Q")
+	test.Error(t, pdf.Close())
+
+	out := buf.String()
+	test.That(t, strings.Contains(out, "/PatternType 1"), "expected tiling pattern")
+	test.That(t, strings.Contains(out, "/Matrix [2.8346457 0 0 2.8346457 0 0]"), "expected pattern matrix in points")
+	test.That(t, strings.Contains(out, "/XStep 2"), "expected horizontal tile step")
+	test.That(t, strings.Contains(out, "1 g 0 0 m 2 0 l 2 2 l 0 2 l f 0 g 0 0 m 1 0 l 1 1 l 0 1 l f 1 1 m 2 1 l 2 2 l 1 2 l f"), "expected tile content")
+
+	// the matrix of a pattern in the tile of another pattern maps to the tile in millimeters
+	tile := canvas.New(4.0, 4.0)
+	ctx := canvas.NewContext(tile)
+	ctx.SetFillPaint(pattern)
+	ctx.DrawPath(1.0, 1.0, canvas.Rectangle(2.0, 2.0))
+	buf.Reset()
+	pdf = New(buf, 10.0, 10.0)
+	pdf.SetCompression(false)
+	style.FillPaint = canvas.NewPattern(tile)
+	pdf.RenderPath(canvas.Rectangle(10.0, 10.0), style, canvas.Identity)
+	test.Error(t, pdf.Close())
+	out = buf.String()
+	test.That(t, strings.Contains(out, "/Matrix [2.8346457 0 0 2.8346457 0 0]"), "expected outer pattern matrix in points")
+	test.That(t, strings.Contains(out, "/Matrix [1 0 0 1 1 1]"), "expected inner pattern matrix in millimeters")
+
+	// a pattern that is used in its own tile is not drawn inside the tile
+	recursive := canvas.NewPattern(tile)
+	ctx.SetFillPaint(recursive)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(1.0, 1.0))
+	buf.Reset()
+	pdf = New(buf, 10.0, 10.0)
+	pdf.SetCompression(false)
+	style.FillPaint = recursive
+	pdf.RenderPath(canvas.Rectangle(10.0, 10.0), style, canvas.Identity)
+	test.Error(t, pdf.Close())
+	test.T(t, strings.Count(buf.String(), "/PatternType 1"), 2)
+}
+
+func TestPDFCMYK(t *testing.T) {
+	pdf := New(&bytes.Buffer{}, 10.0, 10.0)
+	ctx := canvas.NewContext(pdf)
+	ctx.SetFillColor(canvas.CMYK(0.0, 1.0, 1.0, 0.0))
+	ctx.SetStrokeColor(canvas.CMYK(1.0, 0.0, 0.0, 0.6))
+	ctx.SetStrokeWidth(0.5)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(2.0, 2.0))
+	ctx.SetFillColor(color.NRGBA{0xff, 0x00, 0x00, 0x80})
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(2.0, 2.0))
+	test.String(t, pdf.w.String(), " 2.8346457 0 0 2.8346457 0 0 cm 0 1 1 0 k 1 0 0 .6 K .5 w 2 M 0 0 m 2 0 l 2 2 l 0 2 l b 1 0 0 rg /A0 gs 0 0 m 2 0 l 2 2 l 0 2 l f /A1 gs 0 0 m 2 0 l 2 2 l 0 2 l s")
+}
+
+func TestPDFCMYKText(t *testing.T) {
+	dejaVuSerif := canvas.NewFontFamily("dejavu-serif")
+	test.Error(t, dejaVuSerif.LoadFontFile("../font/DejaVuSerif.ttf", canvas.FontRegular))
+	face := dejaVuSerif.Face(12.0, canvas.CMYK(0.0, 1.0, 1.0, 0.0), canvas.FontRegular, canvas.FontNormal)
+
+	pdf := New(&bytes.Buffer{}, 10.0, 10.0)
+	pdf.RenderText(canvas.NewTextLine(face, "T", canvas.Left), canvas.Identity)
+	test.That(t, strings.Contains(pdf.w.String(), " 0 1 1 0 k"), "expected CMYK text color:", pdf.w.String())
+}
+
+func TestPDFCMYKGradient(t *testing.T) {
+	linear := canvas.NewLinearGradient(canvas.Point{X: 0.0, Y: 0.0}, canvas.Point{X: 10.0, Y: 0.0})
+	linear.AddColorStop(0.0, canvas.CMYK(0.0, 1.0, 1.0, 0.0))
+	linear.AddColorStop(1.0, canvas.CMYK(1.0, 0.0, 0.0, 1.0))
+
+	buf := &bytes.Buffer{}
+	pdf := New(buf, 10.0, 10.0)
+	pdf.SetCompression(false)
+	style := canvas.DefaultStyle
+	style.FillColor = linear.Color()
+	style.FillPaint = linear
+	pdf.RenderPath(canvas.Rectangle(10.0, 10.0), style, canvas.Identity)
+	test.Error(t, pdf.Close())
+
+	out := buf.String()
+	test.That(t, strings.Contains(out, "/ColorSpace /DeviceCMYK"), "expected CMYK shading")
+	test.That(t, strings.Contains(out, "/C0 [0 1 1 0]"), "expected CMYK start color")
+	test.That(t, strings.Contains(out, "/C1 [1 0 0 1]"), "expected CMYK end color")
+
+	// mixed color models use RGB
+	linear.AddColorStop(0.5, canvas.Green)
+	buf.Reset()
+	pdf = New(buf, 10.0, 10.0)
+	pdf.SetCompression(false)
+	pdf.RenderPath(canvas.Rectangle(10.0, 10.0), style, canvas.Identity)
+	test.Error(t, pdf.Close())
+	test.That(t, strings.Contains(buf.String(), "/ColorSpace /DeviceRGB"), "expected RGB shading")
+}
@@ -0,0 +1,870 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/rasterizer"
+)
+
+// Template is a page of an existing PDF document that can be drawn onto a canvas, for example to stamp dynamic content onto a pre-designed letterhead or certificate. It implements image.Image so that it can be drawn using canvas.Context.DrawImage or Template.Draw. The PDF renderer embeds the page as a form XObject, keeping its vector graphics, text and fonts intact and including it only once when drawn multiple times. Other renderers draw the page as an image rasterized at Resolution, or lower for very large pages, which supports paths, colors and images but ignores text, shadings and clipping paths.
+type Template struct {
+	// Resolution is used to rasterize the page and determines the size of the template as an image.
+	Resolution canvas.DPMM
+
+	r             *pdfReader
+	box           canvas.Rect   // crop box in points
+	m             canvas.Matrix // transforms the crop box to the upright page with its origin at zero
+	width, height float64       // upright page size in points
+	resources     pdfDict
+	contents      []byte
+
+	mu       sync.Mutex   // guards rasterizing the page
+	snapshot atomic.Value // *templateImage
+}
+
+// templateImage is the page rasterized for a given value of Template.Resolution.
+type templateImage struct {
+	resolution canvas.DPMM
+	img        *image.RGBA
+}
+
+// LoadTemplate loads the page with the given page number (starting at 1) from a PDF file.
+func LoadTemplate(filename string, page int) (*Template, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	templates, err := ReadTemplates(f)
+	if err != nil {
+		return nil, err
+	} else if page < 1 || len(templates) < page {
+		return nil, fmt.Errorf("page %d not found, document has %d pages", page, len(templates))
+	}
+	return templates[page-1], nil
+}
+
+// ReadTemplates reads all pages from a PDF file. Encrypted files and streams encoded with the LZW or run-length filters are not supported.
+func ReadTemplates(r io.Reader) ([]*Template, error) {
+	reader, err := newPDFReader(r)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := reader.pages()
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make([]*Template, 0, len(pages))
+	for i, page := range pages {
+		box, ok := readRect(reader.resolve(page["CropBox"]), reader)
+		if !ok {
+			if box, ok = readRect(reader.resolve(page["MediaBox"]), reader); !ok {
+				box = canvas.Rect{X: 0.0, Y: 0.0, W: 612.0, H: 792.0} // US Letter
+			}
+		}
+		if !(box.W <= maxTemplateSize && box.H <= maxTemplateSize) {
+			return nil, fmt.Errorf("page %d: page size %gx%g exceeds %g points", i+1, box.W, box.H, maxTemplateSize)
+		}
+
+		rotate, _ := reader.resolve(page["Rotate"]).(int)
+		rotate = (rotate/90*90%360 + 360) % 360
+		m := canvas.Identity.Rotate(-float64(rotate))
+		upright := box.Transform(m)
+		m = canvas.Identity.Translate(-upright.X, -upright.Y).Mul(m)
+
+		contents := []byte{}
+		streams, ok := reader.resolve(page["Contents"]).(pdfArray)
+		if !ok {
+			streams = pdfArray{page["Contents"]}
+		}
+		for _, stream := range streams {
+			if stream, ok := reader.resolve(stream).(pdfStream); ok {
+				b, err := reader.decodeStream(stream)
+				if err != nil {
+					return nil, fmt.Errorf("page %d: %w", i+1, err)
+				}
+				contents = append(contents, b...)
+				contents = append(contents, '\n')
+			}
+		}
+
+		resources, _ := reader.resolve(page["Resources"]).(pdfDict)
+		if resources == nil {
+			resources = pdfDict{}
+		}
+
+		templates = append(templates, &Template{
+			Resolution: 150.0 * canvas.DPI,
+			r:          reader,
+			box:        box,
+			m:          m,
+			width:      upright.W,
+			height:     upright.H,
+			resources:  resources,
+			contents:   contents,
+		})
+	}
+	return templates, nil
+}
+
+// maxTemplateSize is the maximum width and height of a page in points, which is the implementation limit of PDF viewers of 200 inches.
+const maxTemplateSize = 14400.0
+
+// maxTemplatePixels is the maximum number of pixels of a rasterized page up to rounding, the resolution is lowered for larger pages.
+const maxTemplatePixels = 1 << 25
+
+// Size returns the width and height of the page in millimeters.
+func (t *Template) Size() (float64, float64) {
+	return t.width / ptPerMm, t.height / ptPerMm
+}
+
+// Draw draws the page with its bottom-left corner at (x,y) at its original size.
+func (t *Template) Draw(ctx *canvas.Context, x, y float64) {
+	ctx.DrawImage(x, y, t, float64(t.resolution()))
+}
+
+// resolution returns Resolution, lowered if needed so that the rasterized page has at most maxTemplatePixels pixels.
+func (t *Template) resolution() canvas.DPMM {
+	w, h := t.Size()
+	if max := math.Sqrt(maxTemplatePixels / (w * h)); max < float64(t.Resolution) {
+		return canvas.DPMM(max)
+	}
+	return t.Resolution
+}
+
+// ColorModel returns the color model of the rasterized page.
+func (t *Template) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+// Bounds returns the bounds of the page rasterized at Resolution. Large pages are rasterized at a lower resolution to limit the image size.
+func (t *Template) Bounds() image.Rectangle {
+	w, h := t.Size()
+	resolution := float64(t.resolution())
+	return image.Rect(0, 0, int(w*resolution+0.5), int(h*resolution+0.5))
+}
+
+// At returns the color of a pixel of the page rasterized at Resolution. The page is rasterized on first use, see Image.
+func (t *Template) At(x, y int) color.Color {
+	return t.Image().At(x, y)
+}
+
+// Image returns the page rasterized at Resolution. The page is rasterized once and the image is kept until Resolution changes, the returned image must not be modified.
+func (t *Template) Image() *image.RGBA {
+	if s, ok := t.snapshot.Load().(*templateImage); ok && s.resolution == t.Resolution {
+		return s.img
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if s, ok := t.snapshot.Load().(*templateImage); ok && s.resolution == t.Resolution {
+		return s.img
+	}
+	s := &templateImage{t.Resolution, rasterizer.Draw(t.canvas(), t.resolution())}
+	t.snapshot.Store(s)
+	return s.img
+}
+
+// canvas returns the page drawn onto a canvas, see Template for what is supported.
+func (t *Template) canvas() *canvas.Canvas {
+	w, h := t.Size()
+	c := canvas.New(w, h)
+	interp := &templateInterpreter{
+		r:          t.r,
+		c:          c,
+		view:       canvas.Identity.Scale(1.0/ptPerMm, 1.0/ptPerMm).Mul(t.m),
+		resolution: float64(t.resolution()),
+		state:      newTemplateState(),
+		path:       &canvas.Path{},
+	}
+	interp.run(t.contents, t.resources)
+	return c
+}
+
+func readRect(val interface{}, r *pdfReader) (canvas.Rect, bool) {
+	array, ok := val.(pdfArray)
+	if !ok || len(array) != 4 {
+		return canvas.Rect{}, false
+	}
+	f := [4]float64{}
+	for i := range f {
+		if f[i], ok = readNumber(r.resolve(array[i])); !ok {
+			return canvas.Rect{}, false
+		}
+	}
+	rect := canvas.Rect{X: math.Min(f[0], f[2]), Y: math.Min(f[1], f[3]), W: math.Abs(f[2] - f[0]), H: math.Abs(f[3] - f[1])}
+	return rect, 0.0 < rect.W && 0.0 < rect.H
+}
+
+func readNumber(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0.0, false
+}
+
+////////////////////////////////////////////////////////////////
+
+// DrawTemplate draws the page of a template as a form XObject, where m transforms the image space of the template to the page as for DrawImage.
+func (w *pdfPageWriter) DrawTemplate(t *Template, m canvas.Matrix) {
+	size := t.Bounds().Size()
+	if size.X == 0 || size.Y == 0 {
+		return
+	}
+
+	ref := w.pdf.writeTemplate(t)
+	if _, ok := w.resources["XObject"]; !ok {
+		w.resources["XObject"] = pdfDict{}
+	}
+	xobjects := w.resources["XObject"].(pdfDict)
+	name := pdfName("")
+	for key, val := range xobjects {
+		if val == ref {
+			name = key
+			break
+		}
+	}
+	if name == "" {
+		name = pdfName(fmt.Sprintf("Fm%d", len(xobjects)))
+		xobjects[name] = ref
+	}
+
+	// image space is scaled to the page in points, which is transformed to the crop box of the form
+	scale := float64(t.resolution()) / ptPerMm
+	m = m.Scale(scale, scale).Mul(t.m)
+	w.SetAlpha(1.0)
+	fmt.Fprintf(w, " q %v %v %v %v %v %v cm /%v Do Q", dec(m[0][0]), dec(m[1][0]), dec(m[0][1]), dec(m[1][1]), dec(m[0][2]), dec(m[1][2]), name)
+}
+
+// writeTemplate writes the form XObject of a template once and returns its reference. Objects that are shared between templates of the same document, such as fonts, are written only once.
+func (w *pdfWriter) writeTemplate(t *Template) pdfRef {
+	if ref, ok := w.templates[t]; ok {
+		return ref
+	}
+	refs, ok := w.imports[t.r]
+	if !ok {
+		refs = map[pdfRef]pdfRef{}
+		w.imports[t.r] = refs
+	}
+
+	dict := pdfDict{
+		"Type":      pdfName("XObject"),
+		"Subtype":   pdfName("Form"),
+		"BBox":      pdfArray{t.box.X, t.box.Y, t.box.X + t.box.W, t.box.Y + t.box.H},
+		"Resources": w.importVal(t.r, t.resources, refs),
+	}
+	if w.compress {
+		dict["Filter"] = pdfFilterFlate
+	}
+
+	// the form inherits the graphics state from where it is drawn, but the page expects the default state
+	stream := append([]byte("1 w 0 J 0 j 10 M [] 0 d 0 g 0 G\n"), t.contents...)
+	ref := w.writeObject(pdfStream{
+		dict:   dict,
+		stream: stream,
+	})
+	w.templates[t] = ref
+	return ref
+}
+
+// importVal copies a value from an imported PDF file, writing the objects it references to the output and remapping their references. References to pages and page tree nodes are dropped so that only the objects used by the page contents are imported.
+func (w *pdfWriter) importVal(r *pdfReader, val interface{}, refs map[pdfRef]pdfRef) interface{} {
+	switch v := val.(type) {
+	case pdfRef:
+		if ref, ok := refs[v]; ok {
+			return ref
+		}
+		obj, err := r.getObject(v)
+		if err != nil || obj == nil {
+			return nil
+		} else if dict, ok := obj.(pdfDict); ok && (dict["Type"] == pdfName("Page") || dict["Type"] == pdfName("Pages")) {
+			return nil
+		}
+		ref := w.reserveObject()
+		refs[v] = ref
+		w.writeReservedObject(ref, w.importVal(r, obj, refs))
+		return ref
+	case pdfDict:
+		dict := pdfDict{}
+		for key, val := range v {
+			dict[key] = w.importVal(r, val, refs)
+		}
+		return dict
+	case pdfArray:
+		array := make(pdfArray, len(v))
+		for i, val := range v {
+			array[i] = w.importVal(r, val, refs)
+		}
+		return array
+	case pdfStream:
+		dict := pdfDict{}
+		for key, val := range v.dict {
+			if key != "Length" {
+				dict[key] = w.importVal(r, val, refs)
+			}
+		}
+		return pdfRawStream{dict: dict, stream: v.stream}
+	case pdfKeyword:
+		return nil
+	}
+	return val
+}
+
+////////////////////////////////////////////////////////////////
+
+type templateState struct {
+	m                      canvas.Matrix
+	fillSpace, strokeSpace templateColorSpace
+	fillColor, strokeColor [3]float64
+	fillAlpha, strokeAlpha float64
+	lineWidth              float64
+	capper                 canvas.Capper
+	joiner                 canvas.Joiner
+	lineJoin               int
+	miterLimit             float64
+	dashOffset             float64
+	dashes                 []float64
+}
+
+func newTemplateState() templateState {
+	return templateState{
+		m:           canvas.Identity,
+		fillSpace:   templateColorSpace{n: 1},
+		strokeSpace: templateColorSpace{n: 1},
+		fillAlpha:   1.0,
+		strokeAlpha: 1.0,
+		lineWidth:   1.0,
+		capper:      canvas.ButtCap,
+		joiner:      canvas.MiterClipJoin(canvas.BevelJoin, 10.0),
+		miterLimit:  10.0,
+	}
+}
+
+// templateInterpreter draws a content stream onto a canvas.
+type templateInterpreter struct {
+	r          *pdfReader
+	c          *canvas.Canvas
+	view       canvas.Matrix // transforms points to millimeters on the canvas
+	resolution float64
+
+	state templateState
+	stack []templateState
+	path  *canvas.Path
+	depth int
+}
+
+func (t *templateInterpreter) run(content []byte, resources pdfDict) {
+	l := &pdfLexer{b: content}
+	operands := []interface{}{}
+	for {
+		val, err := l.value()
+		if err != nil {
+			return
+		}
+		op, ok := val.(pdfKeyword)
+		if !ok {
+			operands = append(operands, val)
+			continue
+		}
+
+		if op == "ID" {
+			// skip inline image data
+			for l.pos++; l.pos+2 < len(l.b); l.pos++ {
+				if isWhitespace(l.b[l.pos-1]) && l.hasPrefix("EI") && (l.pos+2 == len(l.b) || isWhitespace(l.b[l.pos+2])) {
+					l.pos += 2
+					break
+				}
+			}
+		} else {
+			t.operator(string(op), operands, resources)
+		}
+		operands = operands[:0]
+	}
+}
+
+func (t *templateInterpreter) operator(op string, operands []interface{}, resources pdfDict) {
+	nums := make([]float64, 0, len(operands))
+	for _, operand := range operands {
+		if f, ok := readNumber(operand); ok {
+			nums = append(nums, f)
+		}
+	}
+
+	state := &t.state
+	switch op {
+	case "q":
+		t.stack = append(t.stack, *state)
+	case "Q":
+		if 0 < len(t.stack) {
+			*state = t.stack[len(t.stack)-1]
+			t.stack = t.stack[:len(t.stack)-1]
+		}
+	case "cm":
+		if len(nums) == 6 {
+			state.m = state.m.Mul(canvas.Matrix{{nums[0], nums[2], nums[4]}, {nums[1], nums[3], nums[5]}})
+		}
+	case "w", "J", "j", "M":
+		if len(nums) == 1 {
+			t.setLineStyle(pdfName(op), nums[0])
+		}
+	case "d":
+		if len(operands) == 2 {
+			t.setDashes(operands[0], operands[1])
+		}
+	case "gs":
+		if len(operands) == 1 {
+			states, _ := t.r.resolve(resources["ExtGState"]).(pdfDict)
+			if gs, ok := t.r.resolve(states[operandName(operands[0])]).(pdfDict); ok {
+				t.setGraphicsState(gs)
+			}
+		}
+	case "m":
+		if len(nums) == 2 {
+			t.path.MoveTo(nums[0], nums[1])
+		}
+	case "l":
+		if len(nums) == 2 {
+			t.path.LineTo(nums[0], nums[1])
+		}
+	case "c":
+		if len(nums) == 6 {
+			t.path.CubeTo(nums[0], nums[1], nums[2], nums[3], nums[4], nums[5])
+		}
+	case "v":
+		if len(nums) == 4 {
+			start := t.path.Pos()
+			t.path.CubeTo(start.X, start.Y, nums[0], nums[1], nums[2], nums[3])
+		}
+	case "y":
+		if len(nums) == 4 {
+			t.path.CubeTo(nums[0], nums[1], nums[2], nums[3], nums[2], nums[3])
+		}
+	case "h":
+		t.path.Close()
+	case "re":
+		if len(nums) == 4 {
+			t.path.MoveTo(nums[0], nums[1])
+			t.path.LineTo(nums[0]+nums[2], nums[1])
+			t.path.LineTo(nums[0]+nums[2], nums[1]+nums[3])
+			t.path.LineTo(nums[0], nums[1]+nums[3])
+			t.path.Close()
+		}
+	case "S", "s", "f", "F", "f*", "B", "B*", "b", "b*", "n":
+		if op == "s" || op == "b" || op == "b*" {
+			t.path.Close()
+		}
+		fillRule := canvas.NonZero
+		if op[len(op)-1] == '*' {
+			fillRule = canvas.EvenOdd
+		}
+		fill := op != "S" && op != "s" && op != "n"
+		stroke := op == "S" || op == "s" || op[0] == 'B' || op[0] == 'b'
+		t.paint(fill, stroke, fillRule)
+		t.path = &canvas.Path{}
+	case "g", "rg", "k", "G", "RG", "K":
+		n := map[string]int{"g": 1, "rg": 3, "k": 4, "G": 1, "RG": 3, "K": 4}[op]
+		if len(nums) == n {
+			if 'a' <= op[0] {
+				state.fillSpace = templateColorSpace{n: n}
+				state.fillColor = state.fillSpace.rgb(nums)
+			} else {
+				state.strokeSpace = templateColorSpace{n: n}
+				state.strokeColor = state.strokeSpace.rgb(nums)
+			}
+		}
+	case "cs", "CS":
+		if len(operands) == 1 {
+			space := t.colorSpace(operands[0], resources, 0)
+			if op == "cs" {
+				state.fillSpace = space
+				state.fillColor = space.rgb(space.initial())
+			} else {
+				state.strokeSpace = space
+				state.strokeColor = space.rgb(space.initial())
+			}
+		}
+	case "sc", "scn":
+		if 0 < len(nums) && len(nums) == state.fillSpace.components() {
+			state.fillColor = state.fillSpace.rgb(nums)
+		}
+	case "SC", "SCN":
+		if 0 < len(nums) && len(nums) == state.strokeSpace.components() {
+			state.strokeColor = state.strokeSpace.rgb(nums)
+		}
+	case "Do":
+		if len(operands) == 1 {
+			xobjects, _ := t.r.resolve(resources["XObject"]).(pdfDict)
+			if stream, ok := t.r.resolve(xobjects[operandName(operands[0])]).(pdfStream); ok {
+				t.drawXObject(stream, resources)
+			}
+		}
+	}
+}
+
+func operandName(val interface{}) pdfName {
+	name, _ := val.(pdfName)
+	return name
+}
+
+func (t *templateInterpreter) setLineStyle(key pdfName, f float64) {
+	state := &t.state
+	switch key {
+	case "w", "LW":
+		state.lineWidth = f
+	case "J", "LC":
+		state.capper = canvas.ButtCap
+		if f == 1.0 {
+			state.capper = canvas.RoundCap
+		} else if f == 2.0 {
+			state.capper = canvas.SquareCap
+		}
+	case "j", "LJ":
+		state.lineJoin = int(f)
+	case "M", "ML":
+		state.miterLimit = f
+	}
+	state.joiner = canvas.MiterClipJoin(canvas.BevelJoin, state.miterLimit)
+	if state.lineJoin == 1 {
+		state.joiner = canvas.RoundJoin
+	} else if state.lineJoin == 2 {
+		state.joiner = canvas.BevelJoin
+	}
+}
+
+func (t *templateInterpreter) setDashes(array, phase interface{}) {
+	dashes, _ := t.r.resolve(array).(pdfArray)
+	t.state.dashOffset, _ = readNumber(t.r.resolve(phase))
+	t.state.dashes = nil
+	length := 0.0
+	for _, dash := range dashes {
+		f, _ := readNumber(t.r.resolve(dash))
+		if f < 0.0 {
+			t.state.dashes = nil
+			return
+		}
+		t.state.dashes = append(t.state.dashes, f)
+		length += f
+	}
+	if length == 0.0 {
+		t.state.dashes = nil
+	}
+}
+
+func (t *templateInterpreter) setGraphicsState(gs pdfDict) {
+	for key, val := range gs {
+		val = t.r.resolve(val)
+		switch key {
+		case "LW", "LC", "LJ", "ML":
+			if f, ok := readNumber(val); ok {
+				t.setLineStyle(key, f)
+			}
+		case "D":
+			if array, ok := val.(pdfArray); ok && len(array) == 2 {
+				t.setDashes(array[0], array[1])
+			}
+		case "CA":
+			if f, ok := readNumber(val); ok {
+				t.state.strokeAlpha = math.Max(0.0, math.Min(1.0, f))
+			}
+		case "ca":
+			if f, ok := readNumber(val); ok {
+				t.state.fillAlpha = math.Max(0.0, math.Min(1.0, f))
+			}
+		}
+	}
+}
+
+func (t *templateInterpreter) paint(fill, stroke bool, fillRule canvas.FillRule) {
+	if t.path.Empty() {
+		return
+	}
+	m := t.view.Mul(t.state.m)
+	if fill {
+		style := canvas.DefaultStyle
+		style.FillColor = templateColor(t.state.fillColor, t.state.fillAlpha)
+		style.FillRule = fillRule
+		t.c.RenderPath(t.path.Transform(m), style, canvas.Identity)
+	}
+	if stroke {
+		lineWidth := t.state.lineWidth
+		if scale := math.Sqrt(math.Abs(m.Det())); lineWidth*scale*t.resolution < 1.0 && scale != 0.0 {
+			// thinnest line that can be rendered
+			lineWidth = 1.0 / scale / t.resolution
+		}
+		path := t.path
+		if 0 < len(t.state.dashes) {
+			path = path.Dash(t.state.dashOffset, t.state.dashes...)
+		}
+		style := canvas.DefaultStyle
+		style.FillColor = templateColor(t.state.strokeColor, t.state.strokeAlpha)
+		t.c.RenderPath(path.Stroke(lineWidth, t.state.capper, t.state.joiner).Transform(m), style, canvas.Identity)
+	}
+}
+
+func templateColor(rgb [3]float64, alpha float64) color.RGBA {
+	channel := func(c float64) uint8 {
+		return uint8(math.Max(0.0, math.Min(1.0, c))*alpha*255.0 + 0.5)
+	}
+	return color.RGBA{channel(rgb[0]), channel(rgb[1]), channel(rgb[2]), uint8(alpha*255.0 + 0.5)}
+}
+
+func (t *templateInterpreter) drawXObject(stream pdfStream, resources pdfDict) {
+	m := t.view.Mul(t.state.m)
+	switch t.r.resolve(stream.dict["Subtype"]) {
+	case pdfName("Image"):
+		img, err := t.decodeImage(stream, resources)
+		if err != nil {
+			return
+		}
+		size := img.Bounds().Size()
+		t.c.RenderImage(img, m.Scale(1.0/float64(size.X), 1.0/float64(size.Y)))
+	case pdfName("Form"):
+		if 16 < t.depth {
+			return
+		}
+		b, err := t.r.decodeStream(stream)
+		if err != nil {
+			return
+		}
+		if res, ok := t.r.resolve(stream.dict["Resources"]).(pdfDict); ok {
+			resources = res
+		}
+
+		state, path := t.state, t.path
+		if matrix, ok := t.r.resolve(stream.dict["Matrix"]).(pdfArray); ok && len(matrix) == 6 {
+			f := [6]float64{}
+			for i := range f {
+				f[i], _ = readNumber(t.r.resolve(matrix[i]))
+			}
+			t.state.m = t.state.m.Mul(canvas.Matrix{{f[0], f[2], f[4]}, {f[1], f[3], f[5]}})
+		}
+		t.path = &canvas.Path{}
+		t.depth++
+		n := len(t.stack)
+		t.run(b, resources)
+		t.stack = t.stack[:n]
+		t.depth--
+		t.state, t.path = state, path
+	}
+}
+
+// decodeImage decodes an image XObject. JPEG images, and images with at most 8 bits per component in the gray, RGB, CMYK or indexed color spaces are supported, as well as stencil masks and soft masks.
+func (t *templateInterpreter) decodeImage(stream pdfStream, resources pdfDict) (image.Image, error) {
+	width, _ := t.r.resolve(stream.dict["Width"]).(int)
+	height, _ := t.r.resolve(stream.dict["Height"]).(int)
+	bpc, _ := t.r.resolve(stream.dict["BitsPerComponent"]).(int)
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("bad image size")
+	}
+
+	var img image.Image
+	if t.r.resolve(stream.dict["Filter"]) == pdfName("DCTDecode") {
+		var err error
+		if img, err = jpeg.Decode(bytes.NewReader(stream.stream)); err != nil {
+			return nil, err
+		}
+	} else {
+		b, err := t.r.decodeStream(stream)
+		if err != nil {
+			return nil, err
+		}
+
+		if mask, _ := t.r.resolve(stream.dict["ImageMask"]).(bool); mask {
+			// stencil mask painted with the fill color
+			paint := byte(0)
+			if decode, ok := t.r.resolve(stream.dict["Decode"]).(pdfArray); ok && 0 < len(decode) {
+				if f, _ := readNumber(t.r.resolve(decode[0])); f == 1.0 {
+					paint = 1
+				}
+			}
+			col := templateColor(t.state.fillColor, t.state.fillAlpha)
+			rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+			samples := newImageSamples(b, width, 1, 1)
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					if samples.at(x, y, 0) == paint {
+						rgba.SetRGBA(x, y, col)
+					}
+				}
+			}
+			return rgba, nil
+		}
+
+		space := t.colorSpace(stream.dict["ColorSpace"], resources, 0)
+		n := space.components()
+		if n == 0 || bpc <= 0 || 8 < bpc {
+			return nil, fmt.Errorf("unsupported image")
+		}
+		max := float64(int(1)<<bpc - 1)
+		samples := newImageSamples(b, width, n, bpc)
+		nrgba := image.NewNRGBA(image.Rect(0, 0, width, height))
+		comps := make([]float64, n)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				for i := range comps {
+					comps[i] = float64(samples.at(x, y, i))
+					if space.lookup == nil {
+						comps[i] /= max
+					}
+				}
+				rgb := space.rgb(comps)
+				nrgba.SetNRGBA(x, y, color.NRGBA{uint8(rgb[0]*255.0 + 0.5), uint8(rgb[1]*255.0 + 0.5), uint8(rgb[2]*255.0 + 0.5), 255})
+			}
+		}
+		img = nrgba
+	}
+
+	if smask, ok := t.r.resolve(stream.dict["SMask"]).(pdfStream); ok {
+		alpha, err := t.decodeImage(smask, resources)
+		if err != nil {
+			return img, nil
+		}
+		bounds, alphaSize := img.Bounds(), alpha.Bounds().Size()
+		nrgba := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		for y := 0; y < bounds.Dy(); y++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				col := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+				a, _, _, _ := alpha.At(x*alphaSize.X/bounds.Dx(), y*alphaSize.Y/bounds.Dy()).RGBA()
+				col.A = uint8(uint32(col.A) * (a >> 8) / 255)
+				nrgba.SetNRGBA(x, y, col)
+			}
+		}
+		img = nrgba
+	}
+	return img, nil
+}
+
+// imageSamples reads the samples of an image, rows start at byte boundaries.
+type imageSamples struct {
+	b          []byte
+	n, bpc     int
+	rowLen     int
+	sampleMask int
+}
+
+func newImageSamples(b []byte, width, n, bpc int) imageSamples {
+	return imageSamples{b, n, bpc, (width*n*bpc + 7) / 8, 1<<bpc - 1}
+}
+
+func (s imageSamples) at(x, y, i int) byte {
+	bit := (x*s.n + i) * s.bpc
+	j := y*s.rowLen + bit/8
+	if len(s.b) <= j {
+		return 0
+	}
+	shift := 8 - s.bpc - bit%8
+	return byte(int(s.b[j]>>uint(shift)) & s.sampleMask)
+}
+
+// templateColorSpace converts colors to RGB. Only the device, calibrated, ICC-based and indexed color spaces are supported.
+type templateColorSpace struct {
+	n      int // number of components, or zero if unsupported
+	base   *templateColorSpace
+	hival  int
+	lookup []byte
+}
+
+func (t *templateInterpreter) colorSpace(val interface{}, resources pdfDict, depth int) templateColorSpace {
+	val = t.r.resolve(val)
+	if name, ok := val.(pdfName); ok {
+		switch name {
+		case "DeviceGray", "G", "CalGray":
+			return templateColorSpace{n: 1}
+		case "DeviceRGB", "RGB", "CalRGB":
+			return templateColorSpace{n: 3}
+		case "DeviceCMYK", "CMYK":
+			return templateColorSpace{n: 4}
+		}
+		if spaces, ok := t.r.resolve(resources["ColorSpace"]).(pdfDict); ok && depth < 4 {
+			return t.colorSpace(spaces[name], resources, depth+1)
+		}
+		return templateColorSpace{}
+	}
+
+	array, ok := val.(pdfArray)
+	if !ok || len(array) == 0 || 4 < depth {
+		return templateColorSpace{}
+	}
+	switch t.r.resolve(array[0]) {
+	case pdfName("CalGray"):
+		return templateColorSpace{n: 1}
+	case pdfName("CalRGB"):
+		return templateColorSpace{n: 3}
+	case pdfName("ICCBased"):
+		if 1 < len(array) {
+			if stream, ok := t.r.resolve(array[1]).(pdfStream); ok {
+				if n, _ := t.r.resolve(stream.dict["N"]).(int); n == 1 || n == 3 || n == 4 {
+					return templateColorSpace{n: n}
+				}
+			}
+		}
+	case pdfName("Indexed"), pdfName("I"):
+		if len(array) == 4 {
+			base := t.colorSpace(array[1], resources, depth+1)
+			hival, _ := t.r.resolve(array[2]).(int)
+			var lookup []byte
+			switch v := t.r.resolve(array[3]).(type) {
+			case string:
+				lookup = []byte(v)
+			case pdfStream:
+				lookup, _ = t.r.decodeStream(v)
+			}
+			if base.n != 0 && base.lookup == nil && len(lookup) != 0 {
+				return templateColorSpace{n: 1, base: &base, hival: hival, lookup: lookup}
+			}
+		}
+	}
+	return templateColorSpace{}
+}
+
+func (space templateColorSpace) components() int {
+	return space.n
+}
+
+// initial returns the initial color of the color space, which is black for device color spaces.
+func (space templateColorSpace) initial() []float64 {
+	comps := make([]float64, space.n)
+	if space.n == 4 {
+		comps[3] = 1.0
+	}
+	return comps
+}
+
+func (space templateColorSpace) rgb(comps []float64) [3]float64 {
+	if space.lookup != nil && 0 < len(comps) {
+		i := int(math.Max(0.0, math.Min(float64(space.hival), comps[0])))
+		base := make([]float64, space.base.n)
+		for j := range base {
+			if k := i*space.base.n + j; k < len(space.lookup) {
+				base[j] = float64(space.lookup[k]) / 255.0
+			}
+		}
+		return space.base.rgb(base)
+	}
+
+	switch len(comps) {
+	case 1:
+		return [3]float64{comps[0], comps[0], comps[0]}
+	case 3:
+		return [3]float64{comps[0], comps[1], comps[2]}
+	case 4:
+		k := 1.0 - comps[3]
+		return [3]float64{(1.0 - comps[0]) * k, (1.0 - comps[1]) * k, (1.0 - comps[2]) * k}
+	}
+	return [3]float64{}
+}
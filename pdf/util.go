@@ -37,3 +37,22 @@ func (f dec) String() string {
 	}
 	return s
 }
+
+// escapeName escapes the characters of a name that are not regular characters.
+func escapeName(s string) string {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < '!' || '~' < c || c == '#' || isDelimiter(c) {
+			sb := strings.Builder{}
+			sb.WriteString(s[:i])
+			for _, c := range []byte(s[i:]) {
+				if c < '!' || '~' < c || c == '#' || isDelimiter(c) {
+					fmt.Fprintf(&sb, "#%02X", c)
+				} else {
+					sb.WriteByte(c)
+				}
+			}
+			return sb.String()
+		}
+	}
+	return s
+}
@@ -0,0 +1,342 @@
+package plotter
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tdewolff/canvas"
+)
+
+// Format is the command language of a plotter.
+type Format int
+
+// see Format
+const (
+	HPGL Format = iota
+	GCode
+)
+
+// hpglUnitsPerMm is the number of HPGL plotter units per millimeter.
+const hpglUnitsPerMm = 40.0
+
+// Options are the options for plotting.
+type Options struct {
+	// Pens are the colors of the pens in the plotter's carousel, pen i+1 is used for paths with the nearest color to Pens[i]. If empty, each color is assigned the next pen number in order of appearance.
+	Pens []color.Color
+
+	// Optimize reorders and reverses the polylines of each pen to reduce the travel distance with the pen up.
+	Optimize bool
+
+	// PenUp and PenDown are the G-code commands that raise and lower the pen, such as moving a Z-axis or a servo. PenChange is the G-code command that changes pens, where %d is replaced by the pen number. FeedRate is the speed of drawing moves in millimeters per minute.
+	PenUp, PenDown, PenChange string
+	FeedRate                  float64
+}
+
+// DefaultOptions are the default options for plotting.
+var DefaultOptions = Options{
+	Optimize:  true,
+	PenUp:     "G0 Z5",
+	PenDown:   "G1 Z0 F1000",
+	PenChange: "M0 (change to pen %d)",
+	FeedRate:  3000.0,
+}
+
+type polyline struct {
+	points []canvas.Point
+	closed bool
+}
+
+// Plotter is a renderer that writes pen-up and pen-down moves for pen plotters in HPGL or G-code. Paths are flattened to polylines that are drawn with the pen of their stroke color, or of their fill color if they are not stroked, so that filled paths are outlined. Stroke widths are ignored as they are determined by the pen, but dashes are drawn. Text is drawn as outlines and images are not supported.
+type Plotter struct {
+	w             *bufio.Writer
+	width, height float64
+	format        Format
+	opts          *Options
+
+	pens      map[color.RGBA]int
+	polylines map[int][]polyline
+}
+
+// New creates a plotter renderer that writes the given format. Close must be called to write the file. If opts is nil, DefaultOptions are used.
+func New(w io.Writer, width, height float64, format Format, opts *Options) *Plotter {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	return &Plotter{
+		w:         bufio.NewWriter(w),
+		width:     width,
+		height:    height,
+		format:    format,
+		opts:      opts,
+		pens:      map[color.RGBA]int{},
+		polylines: map[int][]polyline{},
+	}
+}
+
+// Size returns the size of the canvas in millimeters.
+func (r *Plotter) Size() (float64, float64) {
+	return r.width, r.height
+}
+
+// RenderPath renders a path to the canvas using a style and a transformation matrix.
+func (r *Plotter) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix) {
+	fill := style.HasFill()
+	stroke := style.HasStroke()
+	if !fill && !stroke {
+		return
+	}
+
+	col := style.FillColor
+	path = path.Transform(m)
+	if stroke {
+		col = style.StrokeColor
+		if 0 < len(style.Dashes) {
+			path = path.Dash(style.DashOffset, style.Dashes...)
+		}
+	}
+
+	pen := r.pen(col)
+	for _, p := range path.Flatten().Split() {
+		points := []canvas.Point{}
+		for _, coord := range p.Coords() {
+			if len(points) == 0 || !coord.Equals(points[len(points)-1]) {
+				points = append(points, coord)
+			}
+		}
+		if len(points) < 2 {
+			continue
+		}
+		r.polylines[pen] = append(r.polylines[pen], polyline{points, p.Closed()})
+	}
+}
+
+// RenderText renders a text object to the canvas using a transformation matrix.
+func (r *Plotter) RenderText(text *canvas.Text, m canvas.Matrix) {
+	canvas.RenderTextAsPath(r, text, m)
+}
+
+// RenderImage renders an image to the canvas using a transformation matrix.
+func (r *Plotter) RenderImage(img image.Image, m canvas.Matrix) {
+	// TODO: (plotter) hatch images
+}
+
+// pen returns the pen number for a color.
+func (r *Plotter) pen(col color.RGBA) int {
+	if pen, ok := r.pens[col]; ok {
+		return pen
+	}
+
+	pen := len(r.pens) + 1
+	if 0 < len(r.opts.Pens) {
+		c := unpremultiply(col)
+		min := math.Inf(1)
+		for i, pc := range r.opts.Pens {
+			penColor := color.RGBAModel.Convert(pc).(color.RGBA)
+			dr := float64(c.R) - float64(penColor.R)
+			dg := float64(c.G) - float64(penColor.G)
+			db := float64(c.B) - float64(penColor.B)
+			if d := 0.3*dr*dr + 0.59*dg*dg + 0.11*db*db; d < min {
+				pen, min = i+1, d
+			}
+		}
+	}
+	r.pens[col] = pen
+	return pen
+}
+
+// Close writes the moves of all pens in order of pen number.
+func (r *Plotter) Close() error {
+	pens := []int{}
+	for pen := range r.polylines {
+		pens = append(pens, pen)
+	}
+	sort.Ints(pens)
+
+	r.begin()
+	pos := canvas.Point{}
+	for _, pen := range pens {
+		polylines := r.polylines[pen]
+		if r.opts.Optimize {
+			polylines = optimize(polylines, pos)
+		}
+
+		r.selectPen(pen, 1 < len(pens))
+		down := false
+		for _, pl := range polylines {
+			if !down || !pl.points[0].Equals(pos) {
+				if down {
+					r.penUp()
+				}
+				r.travel(pl.points[0])
+				r.penDown()
+				down = true
+			}
+			r.draw(pl.points)
+			pos = pl.points[len(pl.points)-1]
+		}
+		if down {
+			r.penUp()
+		}
+	}
+	r.end()
+	return r.w.Flush()
+}
+
+func (r *Plotter) begin() {
+	if r.format == HPGL {
+		fmt.Fprintf(r.w, "IN;\n")
+	} else {
+		fmt.Fprintf(r.w, "G21\nG90\n%s\n", r.opts.PenUp)
+	}
+}
+
+func (r *Plotter) end() {
+	if r.format == HPGL {
+		fmt.Fprintf(r.w, "PU0,0;\nSP0;\n")
+	} else {
+		fmt.Fprintf(r.w, "G0 X0 Y0\nM2\n")
+	}
+}
+
+func (r *Plotter) selectPen(pen int, change bool) {
+	if r.format == HPGL {
+		fmt.Fprintf(r.w, "SP%d;\n", pen)
+	} else if change && r.opts.PenChange != "" {
+		if strings.Contains(r.opts.PenChange, "%d") {
+			fmt.Fprintf(r.w, r.opts.PenChange+"\n", pen)
+		} else {
+			fmt.Fprintf(r.w, "%s\n", r.opts.PenChange)
+		}
+	}
+}
+
+func (r *Plotter) penUp() {
+	if r.format == HPGL {
+		fmt.Fprintf(r.w, "PU;\n")
+	} else {
+		fmt.Fprintf(r.w, "%s\n", r.opts.PenUp)
+	}
+}
+
+func (r *Plotter) penDown() {
+	if r.format == HPGL {
+		fmt.Fprintf(r.w, "PD;\n")
+	} else {
+		fmt.Fprintf(r.w, "%s\n", r.opts.PenDown)
+	}
+}
+
+func (r *Plotter) travel(p canvas.Point) {
+	if r.format == HPGL {
+		fmt.Fprintf(r.w, "PU%d,%d;\n", hpgl(p.X), hpgl(p.Y))
+	} else {
+		fmt.Fprintf(r.w, "G0 X%v Y%v\n", dec(p.X), dec(p.Y))
+	}
+}
+
+// draw draws from the first point through the others with the pen down.
+func (r *Plotter) draw(points []canvas.Point) {
+	if r.format == HPGL {
+		fmt.Fprintf(r.w, "PD")
+		x0, y0 := hpgl(points[0].X), hpgl(points[0].Y)
+		for i, p := range points[1:] {
+			x, y := hpgl(p.X), hpgl(p.Y)
+			if i != 0 && x == x0 && y == y0 {
+				continue
+			} else if i != 0 {
+				fmt.Fprintf(r.w, ",")
+			}
+			fmt.Fprintf(r.w, "%d,%d", x, y)
+			x0, y0 = x, y
+		}
+		fmt.Fprintf(r.w, ";\n")
+	} else {
+		for i, p := range points[1:] {
+			if i == 0 && 0.0 < r.opts.FeedRate {
+				fmt.Fprintf(r.w, "G1 X%v Y%v F%v\n", dec(p.X), dec(p.Y), dec(r.opts.FeedRate))
+			} else {
+				fmt.Fprintf(r.w, "G1 X%v Y%v\n", dec(p.X), dec(p.Y))
+			}
+		}
+	}
+}
+
+// optimize orders the polylines greedily by drawing the nearest polyline next, starting from pos. Open polylines may be reversed and closed polylines may start at any of their points.
+func optimize(polylines []polyline, pos canvas.Point) []polyline {
+	todo := append([]polyline{}, polylines...)
+	ordered := make([]polyline, 0, len(polylines))
+	for 0 < len(todo) {
+		best, bestIndex, bestDist := 0, 0, math.Inf(1)
+		for i, pl := range todo {
+			if pl.closed {
+				for j, p := range pl.points[:len(pl.points)-1] {
+					if d := p.Sub(pos).Length(); d < bestDist {
+						best, bestIndex, bestDist = i, j, d
+					}
+				}
+			} else {
+				if d := pl.points[0].Sub(pos).Length(); d < bestDist {
+					best, bestIndex, bestDist = i, 0, d
+				}
+				if d := pl.points[len(pl.points)-1].Sub(pos).Length(); d < bestDist {
+					best, bestIndex, bestDist = i, len(pl.points)-1, d
+				}
+			}
+		}
+
+		pl := todo[best]
+		todo = append(todo[:best], todo[best+1:]...)
+		if bestIndex != 0 {
+			points := make([]canvas.Point, 0, len(pl.points))
+			if pl.closed {
+				// rotate, the last point equals the first
+				points = append(points, pl.points[bestIndex:len(pl.points)-1]...)
+				points = append(points, pl.points[:bestIndex+1]...)
+			} else {
+				// reverse
+				for i := len(pl.points) - 1; 0 <= i; i-- {
+					points = append(points, pl.points[i])
+				}
+			}
+			pl = polyline{points, pl.closed}
+		}
+		ordered = append(ordered, pl)
+		pos = pl.points[len(pl.points)-1]
+	}
+	return ordered
+}
+
+func unpremultiply(col color.RGBA) color.RGBA {
+	if col.A == 0 || col.A == 255 {
+		return col
+	}
+	return color.RGBA{
+		uint8(float64(col.R) * 255.0 / float64(col.A)),
+		uint8(float64(col.G) * 255.0 / float64(col.A)),
+		uint8(float64(col.B) * 255.0 / float64(col.A)),
+		255,
+	}
+}
+
+// hpgl returns the coordinate in plotter units.
+func hpgl(f float64) int {
+	return int(math.Round(f * hpglUnitsPerMm))
+}
+
+type dec float64
+
+func (f dec) String() string {
+	s := strconv.FormatFloat(float64(f), 'f', 3, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
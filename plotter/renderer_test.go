@@ -0,0 +1,57 @@
+package plotter
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func TestHPGL(t *testing.T) {
+	c := canvas.New(100, 100)
+	ctx := canvas.NewContext(c)
+	ctx.SetFillColor(canvas.Transparent)
+	ctx.SetStrokeColor(canvas.Red)
+	ctx.DrawPath(0, 0, canvas.MustParseSVG("M50 50L60 50"))
+	ctx.SetStrokeColor(canvas.Black)
+	ctx.DrawPath(0, 0, canvas.MustParseSVG("M10 10L10 20L20 20"))
+	ctx.DrawPath(0, 0, canvas.MustParseSVG("M20 20L30 20"))
+	ctx.SetFillColor(canvas.Red)
+	ctx.SetStrokeColor(canvas.Transparent)
+	ctx.DrawPath(0, 0, canvas.MustParseSVG("M1 1L2 1L2 2z"))
+
+	buf := &bytes.Buffer{}
+	test.Error(t, HPGLWriter(nil)(buf, c))
+	test.String(t, buf.String(), "IN;\nSP1;\nPU40,40;\nPD;\nPD80,40,80,80,40,40;\nPU;\nPU2000,2000;\nPD;\nPD2400,2000;\nPU;\nSP2;\nPU1200,800;\nPD;\nPD800,800;\nPD400,800,400,400;\nPU;\nPU0,0;\nSP0;\n")
+}
+
+func TestGCode(t *testing.T) {
+	c := canvas.New(100, 100)
+	ctx := canvas.NewContext(c)
+	ctx.SetFillColor(canvas.Transparent)
+	ctx.SetStrokeColor(color.RGBA{200, 0, 0, 255})
+	ctx.DrawPath(0, 0, canvas.MustParseSVG("M0 0L10 0"))
+	ctx.SetStrokeColor(canvas.Blue)
+	ctx.DrawPath(0, 0, canvas.MustParseSVG("M0 10L10.5 10"))
+
+	opts := DefaultOptions
+	opts.Pens = []color.Color{canvas.Blue, canvas.Red}
+	opts.Optimize = false
+	buf := &bytes.Buffer{}
+	test.Error(t, GCodeWriter(&opts)(buf, c))
+	test.String(t, buf.String(), "G21\nG90\nG0 Z5\nM0 (change to pen 1)\nG0 X0 Y10\nG1 Z0 F1000\nG1 X10.5 Y10 F3000\nG0 Z5\nM0 (change to pen 2)\nG0 X0 Y0\nG1 Z0 F1000\nG1 X10 Y0 F3000\nG0 Z5\nG0 X0 Y0\nM2\n")
+}
+
+func TestOptimize(t *testing.T) {
+	polylines := []polyline{
+		{[]canvas.Point{{X: 10, Y: 0}, {X: 20, Y: 0}}, false},
+		{[]canvas.Point{{X: 5, Y: 0}, {X: 1, Y: 0}}, false},
+		{[]canvas.Point{{X: 30, Y: 0}, {X: 30, Y: 10}, {X: 20, Y: 5}, {X: 30, Y: 0}}, true},
+	}
+	ordered := optimize(polylines, canvas.Point{})
+	test.T(t, ordered[0].points, []canvas.Point{{X: 1, Y: 0}, {X: 5, Y: 0}})
+	test.T(t, ordered[1].points, []canvas.Point{{X: 10, Y: 0}, {X: 20, Y: 0}})
+	test.T(t, ordered[2].points, []canvas.Point{{X: 20, Y: 5}, {X: 30, Y: 0}, {X: 30, Y: 10}, {X: 20, Y: 5}})
+}
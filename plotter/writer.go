@@ -0,0 +1,25 @@
+package plotter
+
+import (
+	"io"
+
+	"github.com/tdewolff/canvas"
+)
+
+// HPGLWriter writes the canvas as a HPGL file for pen plotters. If opts is nil, DefaultOptions are used.
+func HPGLWriter(opts *Options) canvas.Writer {
+	return func(w io.Writer, c *canvas.Canvas) error {
+		plotter := New(w, c.W, c.H, HPGL, opts)
+		c.Render(plotter)
+		return plotter.Close()
+	}
+}
+
+// GCodeWriter writes the canvas as a G-code file for pen plotters and CNC machines holding a pen. If opts is nil, DefaultOptions are used.
+func GCodeWriter(opts *Options) canvas.Writer {
+	return func(w io.Writer, c *canvas.Canvas) error {
+		plotter := New(w, c.W, c.H, GCode, opts)
+		c.Render(plotter)
+		return plotter.Close()
+	}
+}
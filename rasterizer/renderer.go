@@ -2,6 +2,7 @@ package rasterizer
 
 import (
 	"image"
+	"image/color"
 
 	"github.com/tdewolff/canvas"
 	"golang.org/x/image/draw"
@@ -42,7 +43,7 @@ func (r *Renderer) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Ma
 	path = path.Transform(m)
 
 	strokeWidth := 0.0
-	if style.StrokeColor.A != 0 && 0.0 < style.StrokeWidth {
+	if style.HasStroke() {
 		strokeWidth = style.StrokeWidth
 	}
 
@@ -76,13 +77,18 @@ func (r *Renderer) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Ma
 		return // has no size
 	}
 
+	rect := image.Rect(x, size.Y-y, x+w, size.Y-y-h)
 	path = path.Translate(-float64(x)/resolution, -float64(y)/resolution)
-	if style.FillColor.A != 0 {
+	if style.HasFill() {
 		ras := vector.NewRasterizer(w, h)
 		path.ToRasterizer(ras, resolution)
-		ras.Draw(r.img, image.Rect(x, size.Y-y, x+w, size.Y-y-h), image.NewUniform(style.FillColor), image.Point{dx, dy})
+		src, sp := r.source(style.FillColor, style.FillPaint, m), image.Point{dx, dy}
+		if style.FillPaint != nil {
+			sp = rect.Min
+		}
+		ras.Draw(r.img, rect, src, sp)
 	}
-	if style.StrokeColor.A != 0 && 0.0 < style.StrokeWidth {
+	if style.HasStroke() {
 		if 0 < len(style.Dashes) {
 			path = path.Dash(style.DashOffset, style.Dashes...)
 		}
@@ -90,8 +96,21 @@ func (r *Renderer) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Ma
 
 		ras := vector.NewRasterizer(w, h)
 		path.ToRasterizer(ras, resolution)
-		ras.Draw(r.img, image.Rect(x, size.Y-y, x+w, size.Y-y-h), image.NewUniform(style.StrokeColor), image.Point{dx, dy})
+		src, sp := r.source(style.StrokeColor, style.StrokePaint, m), image.Point{dx, dy}
+		if style.StrokePaint != nil {
+			sp = rect.Min
+		}
+		ras.Draw(r.img, rect, src, sp)
+	}
+}
+
+// source returns the source image to draw a solid color or a paint, where m is the transformation of the path.
+func (r *Renderer) source(col color.RGBA, paint canvas.Paint, m canvas.Matrix) image.Image {
+	if paint == nil {
+		return image.NewUniform(col)
 	}
+	h := float64(r.img.Bounds().Size().Y)
+	return canvas.PaintImage(paint, canvas.Identity.Translate(0.0, h).Scale(float64(r.resolution), -float64(r.resolution)).Mul(m))
 }
 
 func (r *Renderer) RenderText(text *canvas.Text, m canvas.Matrix) {
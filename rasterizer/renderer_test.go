@@ -0,0 +1,38 @@
+package rasterizer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func TestRasterizerPaint(t *testing.T) {
+	tile := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	tile.Set(0, 0, canvas.Red)
+	tile.Set(1, 0, canvas.Blue)
+
+	c := canvas.New(10.0, 10.0)
+	ctx := canvas.NewContext(c)
+	ctx.SetFillPaint(canvas.NewImagePaint(tile, 0.2)) // 10x5 mm
+	ctx.DrawPath(0.0, 5.0, canvas.Rectangle(10.0, 5.0))
+
+	img := Draw(c, 1.0)
+	test.T(t, img.At(2, 2), color.Color(canvas.Red))
+	test.T(t, img.At(7, 2), color.Color(canvas.Blue))
+	test.T(t, img.At(2, 7), color.Color(canvas.Transparent))
+}
+
+func TestRasterizerPattern(t *testing.T) {
+	c := canvas.New(4.0, 4.0)
+	ctx := canvas.NewContext(c)
+	ctx.SetFillPaint(canvas.NewCheckerboardPattern(canvas.Black, canvas.White, 1.0))
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(4.0, 4.0))
+
+	img := Draw(c, 10.0)
+	test.T(t, img.At(5, 35), color.Color(canvas.Black)) // bottom-left
+	test.T(t, img.At(15, 35), color.Color(canvas.White))
+	test.T(t, img.At(15, 25), color.Color(canvas.Black))
+}
@@ -0,0 +1,245 @@
+// Package recorder records the rendering commands of a canvas, so that they can be replayed to any renderer or compared in tests.
+package recorder
+
+import (
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tdewolff/canvas"
+)
+
+// Command is a rendering command with its style and transformation resolved. Exactly one of Path, Text or Image is set.
+type Command struct {
+	Path  *canvas.Path
+	Style canvas.Style
+	Text  *canvas.Text
+	Image image.Image
+	M     canvas.Matrix
+}
+
+// Recorder is a renderer that records the rendering commands it receives. The recording can be replayed to other renderers, which is useful to render the same drawing to several formats or to cache it, and it can be written as a compact text format with one command per line, which is useful for golden tests that should not depend on the formatting of the output formats.
+type Recorder struct {
+	width, height float64
+	Commands      []Command
+}
+
+// New creates a recording renderer.
+func New(width, height float64) *Recorder {
+	return &Recorder{
+		width:  width,
+		height: height,
+	}
+}
+
+// Record returns the recording of rendering a canvas.
+func Record(c *canvas.Canvas) *Recorder {
+	r := New(c.W, c.H)
+	c.Render(r)
+	return r
+}
+
+func (r *Recorder) Size() (float64, float64) {
+	return r.width, r.height
+}
+
+func (r *Recorder) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix) {
+	style.Dashes = append([]float64{}, style.Dashes...)
+	r.Commands = append(r.Commands, Command{Path: path.Copy(), Style: style, M: m})
+}
+
+func (r *Recorder) RenderText(text *canvas.Text, m canvas.Matrix) {
+	r.Commands = append(r.Commands, Command{Text: text, M: m})
+}
+
+func (r *Recorder) RenderImage(img image.Image, m canvas.Matrix) {
+	r.Commands = append(r.Commands, Command{Image: img, M: m})
+}
+
+// Replay renders the recorded commands to a renderer.
+func (r *Recorder) Replay(renderer canvas.Renderer) {
+	for _, cmd := range r.Commands {
+		if cmd.Path != nil {
+			renderer.RenderPath(cmd.Path, cmd.Style, cmd.M)
+		} else if cmd.Text != nil {
+			renderer.RenderText(cmd.Text, cmd.M)
+		} else if cmd.Image != nil {
+			renderer.RenderImage(cmd.Image, cmd.M)
+		}
+	}
+}
+
+// String returns the recording in a text format with one command per line. Paths are written with their transformation applied, text is written per span with its baseline position, and images are written by their size and a hash of their pixels. Numbers are rounded to canvas.Precision decimals.
+func (r *Recorder) String() string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "size %v %v\n", num(r.width), num(r.height))
+	for _, cmd := range r.Commands {
+		if cmd.Path != nil {
+			writePath(sb, cmd.Path.Transform(cmd.M), cmd.Style)
+		} else if cmd.Text != nil {
+			writeText(sb, cmd.Text, cmd.M)
+		} else if cmd.Image != nil {
+			writeImage(sb, cmd.Image, cmd.M)
+		}
+	}
+	return sb.String()
+}
+
+func writePath(w io.Writer, path *canvas.Path, style canvas.Style) {
+	fill := style.HasFill()
+	stroke := style.HasStroke()
+
+	fmt.Fprintf(w, "path")
+	if fill {
+		fmt.Fprintf(w, " fill=%v", canvas.CSSColor(style.FillColor))
+		if style.FillPaint != nil {
+			fmt.Fprintf(w, " fill-paint=%v", paint(style.FillPaint))
+		}
+		if style.FillRule == canvas.EvenOdd {
+			fmt.Fprintf(w, " fill-rule=evenodd")
+		}
+	} else {
+		fmt.Fprintf(w, " fill=none")
+	}
+	if stroke {
+		fmt.Fprintf(w, " stroke=%v", canvas.CSSColor(style.StrokeColor))
+		if style.StrokePaint != nil {
+			fmt.Fprintf(w, " stroke-paint=%v", paint(style.StrokePaint))
+		}
+		fmt.Fprintf(w, " stroke-width=%v cap=%v join=%v", num(style.StrokeWidth), style.StrokeCapper, style.StrokeJoiner)
+		if 0 < len(style.Dashes) {
+			fmt.Fprintf(w, " dashes=%v", nums(style.Dashes...))
+			if style.DashOffset != 0.0 {
+				fmt.Fprintf(w, " dash-offset=%v", num(style.DashOffset))
+			}
+		}
+	}
+
+	fmt.Fprintf(w, " d=")
+	path.Iterate(func(start, end canvas.Point) {
+		fmt.Fprintf(w, "M%v", nums(end.X, end.Y))
+	}, func(start, end canvas.Point) {
+		fmt.Fprintf(w, "L%v", nums(end.X, end.Y))
+	}, func(start, cp, end canvas.Point) {
+		fmt.Fprintf(w, "Q%v", nums(cp.X, cp.Y, end.X, end.Y))
+	}, func(start, cp1, cp2, end canvas.Point) {
+		fmt.Fprintf(w, "C%v", nums(cp1.X, cp1.Y, cp2.X, cp2.Y, end.X, end.Y))
+	}, func(start canvas.Point, rx, ry, rot float64, large, sweep bool, end canvas.Point) {
+		iLarge, iSweep := 0.0, 0.0
+		if large {
+			iLarge = 1.0
+		}
+		if sweep {
+			iSweep = 1.0
+		}
+		fmt.Fprintf(w, "A%v", nums(rx, ry, rot, iLarge, iSweep, end.X, end.Y))
+	}, func(start, end canvas.Point) {
+		fmt.Fprintf(w, "z")
+	})
+	fmt.Fprintf(w, "\n")
+}
+
+func writeText(w io.Writer, text *canvas.Text, m canvas.Matrix) {
+	text.WalkSpans(func(y, dx float64, span canvas.TextSpan) {
+		pos := m.Dot(canvas.Point{X: dx, Y: y + span.Face.Voffset})
+		fmt.Fprintf(w, "text x=%v y=%v", num(pos.X), num(pos.Y))
+		if !m.IsTranslation() {
+			fmt.Fprintf(w, " m=%v", nums(m[0][0], m[0][1], m[1][0], m[1][1]))
+		}
+		fmt.Fprintf(w, " font=%q size=%v weight=%d", span.Face.Name(), num(span.Face.Size*span.Face.Scale), span.Face.Boldness())
+		if span.Face.Style&canvas.FontItalic != 0 {
+			fmt.Fprintf(w, " italic")
+		}
+		if span.Face.Variant&canvas.FontSmallcaps != 0 {
+			fmt.Fprintf(w, " small-caps")
+		}
+		fill := canvas.CSSColor(span.Face.Color).String()
+		if span.Face.Paint != nil {
+			fill = paint(span.Face.Paint)
+		}
+		fmt.Fprintf(w, " fill=%v %q\n", fill, span.Text)
+	})
+}
+
+func writeImage(w io.Writer, img image.Image, m canvas.Matrix) {
+	bounds := img.Bounds()
+	fmt.Fprintf(w, "image %dx%d hash=%016x m=%v\n", bounds.Dx(), bounds.Dy(), imageHash(img), nums(m[0][0], m[0][1], m[1][0], m[1][1], m[0][2], m[1][2]))
+}
+
+// paint returns a description of a paint with its parameters.
+func paint(paint canvas.Paint) string {
+	switch paint := paint.(type) {
+	case canvas.ColorPaint:
+		if cmyk, ok := paint.C.(color.CMYK); ok {
+			return fmt.Sprintf("cmyk(%v)", nums(float64(cmyk.C)/255.0, float64(cmyk.M)/255.0, float64(cmyk.Y)/255.0, float64(cmyk.K)/255.0))
+		}
+		return canvas.CSSColor(paint.Color()).String()
+	case *canvas.ImagePaint:
+		bounds := paint.Image.Bounds()
+		m := paint.M
+		return fmt.Sprintf("image(%dx%d,hash=%016x,m=%v)", bounds.Dx(), bounds.Dy(), imageHash(paint.Image), nums(m[0][0], m[0][1], m[1][0], m[1][1], m[0][2], m[1][2]))
+	case *canvas.LinearGradient:
+		return fmt.Sprintf("linear-gradient(%v %v)", nums(paint.Start.X, paint.Start.Y, paint.End.X, paint.End.Y), gradient(paint.Gradient))
+	case *canvas.RadialGradient:
+		return fmt.Sprintf("radial-gradient(%v %v)", nums(paint.C0.X, paint.C0.Y, paint.R0, paint.C1.X, paint.C1.Y, paint.R1), gradient(paint.Gradient))
+	case *canvas.ConicGradient:
+		return fmt.Sprintf("conic-gradient(%v %v)", nums(paint.Center.X, paint.Center.Y, paint.Angle), gradient(paint.Gradient))
+	case *canvas.Pattern:
+		m := paint.M
+		return fmt.Sprintf("pattern(%vx%v hash=%016x spacing=%v m=%v)", num(paint.Tile.W), num(paint.Tile.H), paint.Tile.Hash(), nums(paint.Spacing.X, paint.Spacing.Y), nums(m[0][0], m[0][1], m[1][0], m[1][1], m[0][2], m[1][2]))
+	}
+	return fmt.Sprintf("%T", paint)
+}
+
+// gradient returns a description of the stops, spread method and transformation of a gradient.
+func gradient(g canvas.Gradient) string {
+	stops := make([]string, len(g.Stops))
+	for i, stop := range g.Stops {
+		col := canvas.CSSColor(stop.Color).String()
+		if stop.Paint != nil {
+			col = paint(stop.Paint)
+		}
+		stops[i] = fmt.Sprintf("%v:%v", num(stop.Offset), col)
+	}
+	m := g.M
+	return fmt.Sprintf("stops=%v spread=%v m=%v", strings.Join(stops, "/"), g.Spread, nums(m[0][0], m[0][1], m[1][0], m[1][1], m[0][2], m[1][2]))
+}
+
+// imageHash returns a hash of the image pixels.
+func imageHash(img image.Image) uint64 {
+	h := fnv.New64a()
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			h.Write([]byte{c.R, c.G, c.B, c.A})
+		}
+	}
+	return h.Sum64()
+}
+
+type num float64
+
+func (f num) String() string {
+	s := strconv.FormatFloat(float64(f), 'f', canvas.Precision, 64)
+	if strings.IndexByte(s, '.') != -1 {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
+
+// nums returns the numbers separated by commas.
+func nums(fs ...float64) string {
+	ss := make([]string, len(fs))
+	for i, f := range fs {
+		ss[i] = num(f).String()
+	}
+	return strings.Join(ss, ",")
+}
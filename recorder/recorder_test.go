@@ -0,0 +1,74 @@
+package recorder
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func TestRecorder(t *testing.T) {
+	dejaVuSerif := canvas.NewFontFamily("dejavu-serif")
+	test.Error(t, dejaVuSerif.LoadFontFile("../font/DejaVuSerif.ttf", canvas.FontRegular))
+	face := dejaVuSerif.Face(12.0, canvas.Black, canvas.FontRegular, canvas.FontNormal)
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+
+	c := canvas.New(100.0, 50.0)
+	ctx := canvas.NewContext(c)
+	ctx.SetFillColor(canvas.Red)
+	ctx.DrawPath(10.0, 10.0, canvas.Rectangle(20.0, 10.0))
+	ctx.SetFillColor(canvas.Transparent)
+	ctx.SetStrokeColor(canvas.Blue)
+	ctx.SetStrokeWidth(0.5)
+	ctx.SetDashes(1.0, 2.0, 3.0)
+	ctx.DrawPath(0.0, 0.0, canvas.MustParseSVG("M0 0L10 0Q15 5 20 0"))
+	ctx.DrawText(10.0, 40.0, canvas.NewTextLine(face, "Hi", canvas.Left))
+	ctx.DrawImage(50.0, 10.0, img, 1.0)
+
+	rec := Record(c)
+	test.T(t, len(rec.Commands), 4)
+	lines := strings.Split(rec.String(), "\n")
+	test.T(t, len(lines), 6)
+	test.String(t, lines[0], "size 100 50")
+	test.String(t, lines[1], "path fill=#f00 d=M10,10L30,10L30,20L10,20z")
+	test.String(t, lines[2], "path fill=none stroke=#00f stroke-width=0.5 cap=Butt join=MiterClip dashes=2,3 dash-offset=1 d=M0,0L10,0Q15,5,20,0")
+	test.That(t, strings.HasPrefix(lines[3], "text x=10 y=40 font=\"dejavu-serif\" size=4.23333333 weight=400 fill=#000 \"Hi\""), "bad text command:", lines[3])
+	test.That(t, strings.HasPrefix(lines[4], "image 2x1 hash="), "bad image command:", lines[4])
+	test.That(t, strings.HasSuffix(lines[4], " m=1,0,0,1,50,10"), "bad image command:", lines[4])
+
+	// replaying gives the same recording
+	replay := New(rec.Size())
+	rec.Replay(replay)
+	test.String(t, replay.String(), rec.String())
+
+	buf := &bytes.Buffer{}
+	test.Error(t, Writer(buf, c))
+	test.String(t, buf.String(), rec.String())
+}
+
+func TestRecorderCMYK(t *testing.T) {
+	dejaVuSerif := canvas.NewFontFamily("dejavu-serif")
+	test.Error(t, dejaVuSerif.LoadFontFile("../font/DejaVuSerif.ttf", canvas.FontRegular))
+	cmyk := canvas.CMYK(0.0, 1.0, 1.0, 0.0)
+	face := dejaVuSerif.Face(12.0, cmyk, canvas.FontRegular, canvas.FontNormal)
+
+	linear := canvas.NewLinearGradient(canvas.Point{X: 0.0, Y: 0.0}, canvas.Point{X: 10.0, Y: 0.0})
+	linear.AddColorStop(0.0, cmyk)
+	linear.AddColorStop(1.0, canvas.Blue)
+
+	c := canvas.New(100.0, 50.0)
+	ctx := canvas.NewContext(c)
+	ctx.SetFillPaint(linear)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(10.0, 10.0))
+	ctx.DrawText(10.0, 40.0, canvas.NewTextLine(face, "Hi", canvas.Left))
+
+	lines := strings.Split(Record(c).String(), "\n")
+	test.That(t, strings.Contains(lines[1], "stops=0:cmyk(0,1,1,0)/1:#00f"), "bad gradient stops:", lines[1])
+	test.That(t, strings.Contains(lines[2], "fill=cmyk(0,1,1,0) \"Hi\""), "bad text fill:", lines[2])
+}
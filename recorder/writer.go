@@ -0,0 +1,13 @@
+package recorder
+
+import (
+	"io"
+
+	"github.com/tdewolff/canvas"
+)
+
+// Writer writes the rendering commands of the canvas in the text format of Recorder.String.
+func Writer(w io.Writer, c *canvas.Canvas) error {
+	_, err := io.WriteString(w, Record(c).String())
+	return err
+}
@@ -0,0 +1,111 @@
+package canvas
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Stats are the statistics of writing a canvas, see Canvas.WriteStats. The time spent by the writer is split into the time spent rendering the layers (converting and encoding paths, texts and images), the time spent writing the output, and the remaining time which is mostly spent encoding the document when it is finished (eg. compression or rasterization).
+type Stats struct {
+	Layers   int   // number of layers rendered
+	Paths    int   // number of paths rendered
+	Segments int   // number of path segments rendered, excluding MoveTos
+	Texts    int   // number of texts rendered
+	Glyphs   int   // number of glyphs laid out in the rendered texts
+	Images   int   // number of images rendered
+	Bytes    int64 // number of bytes written
+
+	Render time.Duration // time spent rendering layers, excluding writing the output
+	Output time.Duration // time spent writing the output
+	Encode time.Duration // remaining time spent by the writer
+	Total  time.Duration // total time spent by the writer
+}
+
+// String returns the statistics on a single line, suitable for logs.
+func (s Stats) String() string {
+	return fmt.Sprintf("layers=%d paths=%d segments=%d texts=%d glyphs=%d images=%d bytes=%d render=%v output=%v encode=%v total=%v", s.Layers, s.Paths, s.Segments, s.Texts, s.Glyphs, s.Images, s.Bytes, s.Render, s.Output, s.Encode, s.Total)
+}
+
+// Hooks are optional callbacks that are called while writing a canvas with Canvas.WriteStats, which can be used to trace or profile rendering. Any of the callbacks may be nil.
+type Hooks struct {
+	Layer func(i int, d time.Duration) // called after rendering the i-th layer with the time it took
+	Write func(n int, d time.Duration) // called after writing n bytes to the output with the time it took
+	Done  func(stats Stats, err error) // called when the writer has finished
+}
+
+// WriteStats writes the canvas to w using the given Writer, like WriteFile, and returns statistics of the rendered layers, the output size and the time spent per stage. Hooks may be nil.
+func (c *Canvas) WriteStats(w io.Writer, writer Writer, hooks *Hooks) (Stats, error) {
+	if hooks == nil {
+		hooks = &Hooks{}
+	}
+	s := &statsRecorder{hooks: hooks, w: w}
+
+	// shallow copy so that the canvas can be written concurrently
+	cs := *c
+	cs.stats = s
+
+	start := time.Now()
+	err := writer(s, &cs)
+	s.Total = time.Since(start)
+	s.Encode = s.Total - s.Render - s.Output
+	if hooks.Done != nil {
+		hooks.Done(s.Stats, err)
+	}
+	return s.Stats, err
+}
+
+// statsRecorder counts the rendered layers and the bytes written for Canvas.WriteStats.
+type statsRecorder struct {
+	Stats
+	hooks *Hooks
+	w     io.Writer
+}
+
+func (s *statsRecorder) Write(b []byte) (int, error) {
+	start := time.Now()
+	n, err := s.w.Write(b)
+	d := time.Since(start)
+	s.Bytes += int64(n)
+	s.Output += d
+	if s.hooks.Write != nil {
+		s.hooks.Write(n, d)
+	}
+	return n, err
+}
+
+// render renders the i-th layer and keeps track of its statistics.
+func (s *statsRecorder) render(r Renderer, i int, l layer, m Matrix) {
+	output := s.Output
+	start := time.Now()
+	if l.path != nil {
+		r.RenderPath(l.path, l.style, m)
+	} else if l.text != nil {
+		r.RenderText(l.text, m)
+	} else if l.img != nil {
+		r.RenderImage(l.img, m)
+	}
+	d := time.Since(start)
+	s.Render += d - (s.Output - output)
+
+	s.Layers++
+	if l.path != nil {
+		s.Paths++
+		for j := 0; j < len(l.path.d); {
+			if l.path.d[j] != moveToCmd {
+				s.Segments++
+			}
+			j += cmdLen(l.path.d[j])
+		}
+	} else if l.text != nil {
+		s.Texts++
+		l.text.WalkSpans(func(_, _ float64, span TextSpan) {
+			s.Glyphs += span.CountGlyphs()
+		})
+	} else if l.img != nil {
+		s.Images++
+	}
+	if s.hooks.Layer != nil {
+		s.hooks.Layer(i, d)
+	}
+}
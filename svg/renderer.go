@@ -21,7 +21,10 @@ type SVG struct {
 	embedFonts    bool
 	fonts         map[*canvas.Font]bool
 	maskID        int
+	gradientID    int
+	patternID     int
 	imgEnc        canvas.ImageEncoding
+	patterns      []*canvas.Pattern // patterns whose tiles are being written
 
 	classes []string
 }
@@ -109,8 +112,22 @@ func (r *SVG) Size() (float64, float64) {
 }
 
 func (r *SVG) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix) {
-	fill := style.FillColor.A != 0
-	stroke := style.StrokeColor.A != 0 && 0.0 < style.StrokeWidth
+	if !paintSupported(style.FillPaint) || !paintSupported(style.StrokePaint) {
+		canvas.RenderPaintAsImage(r, path, style, m)
+		return
+	}
+
+	fill := style.HasFill()
+	stroke := style.HasStroke()
+
+	fillColor := canvas.CSSColor(style.FillColor).String()
+	if fill && style.FillPaint != nil {
+		fillColor = r.writePaint(style.FillPaint, m)
+	}
+	strokeColor := canvas.CSSColor(style.StrokeColor).String()
+	if stroke && style.StrokePaint != nil {
+		strokeColor = r.writePaint(style.StrokePaint, m)
+	}
 
 	path = path.Transform(canvas.Identity.ReflectYAbout(r.height / 2.0).Mul(m))
 	fmt.Fprintf(r.w, `<path d="%s`, path.ToSVG())
@@ -128,8 +145,8 @@ func (r *SVG) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix)
 
 	if !stroke {
 		if fill {
-			if style.FillColor != canvas.Black {
-				fmt.Fprintf(r.w, `" fill="%v`, canvas.CSSColor(style.FillColor))
+			if fillColor != "#000" {
+				fmt.Fprintf(r.w, `" fill="%v`, fillColor)
 			}
 			if style.FillRule == canvas.EvenOdd {
 				fmt.Fprintf(r.w, `" fill-rule="evenodd`)
@@ -140,8 +157,8 @@ func (r *SVG) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix)
 	} else {
 		b := &strings.Builder{}
 		if fill {
-			if style.FillColor != canvas.Black {
-				fmt.Fprintf(b, ";fill:%v", canvas.CSSColor(style.FillColor))
+			if fillColor != "#000" {
+				fmt.Fprintf(b, ";fill:%v", fillColor)
 			}
 			if style.FillRule == canvas.EvenOdd {
 				fmt.Fprintf(b, ";fill-rule:evenodd")
@@ -150,7 +167,7 @@ func (r *SVG) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix)
 			fmt.Fprintf(b, ";fill:none")
 		}
 		if stroke && !strokeUnsupported {
-			fmt.Fprintf(b, `;stroke:%v`, canvas.CSSColor(style.StrokeColor))
+			fmt.Fprintf(b, `;stroke:%v`, strokeColor)
 			if style.StrokeWidth != 1.0 {
 				fmt.Fprintf(b, ";stroke-width:%v", dec(style.StrokeWidth))
 			}
@@ -203,8 +220,8 @@ func (r *SVG) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix)
 		}
 		path = path.Stroke(style.StrokeWidth, style.StrokeCapper, style.StrokeJoiner)
 		fmt.Fprintf(r.w, `<path d="%s`, path.ToSVG())
-		if style.StrokeColor != canvas.Black {
-			fmt.Fprintf(r.w, `" fill="%v`, canvas.CSSColor(style.StrokeColor))
+		if strokeColor != "#000" {
+			fmt.Fprintf(r.w, `" fill="%v`, strokeColor)
 		}
 		if style.FillRule == canvas.EvenOdd {
 			fmt.Fprintf(r.w, `" fill-rule="evenodd`)
@@ -214,6 +231,115 @@ func (r *SVG) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix)
 	}
 }
 
+// paintSupported returns true if the paint is a solid color, a linear or radial gradient, or a pattern, which can be written natively. SVG 1.1 has no start radius for radial gradients, so those with a non-zero start radius are only supported when the circles are concentric and padded, in which case the stops are remapped.
+func paintSupported(paint canvas.Paint) bool {
+	switch p := paint.(type) {
+	case nil, canvas.ColorPaint, *canvas.LinearGradient, *canvas.Pattern:
+		return true
+	case *canvas.RadialGradient:
+		return p.R0 == 0.0 || p.C0.Equals(p.C1) && p.Spread == canvas.PadSpread && 0.0 < p.R0 && p.R0 < p.R1
+	}
+	return false
+}
+
+// writePaint writes the definition of a paint for a path with transformation m and returns a reference to it, or returns the color of a color paint.
+func (r *SVG) writePaint(paint canvas.Paint, m canvas.Matrix) string {
+	switch p := paint.(type) {
+	case canvas.ColorPaint:
+		return canvas.CSSColor(p.Color()).String()
+	case *canvas.Pattern:
+		return r.writePattern(p, m)
+	}
+	return r.writeGradient(paint, m)
+}
+
+// writePattern writes the definition of a pattern for a path with transformation m and returns a reference to it. The tile is rendered by a renderer that writes to the same output and shares the identifiers of the definitions, a pattern that is used in its own tile is not drawn.
+func (r *SVG) writePattern(pattern *canvas.Pattern, m canvas.Matrix) string {
+	for _, p := range r.patterns {
+		if p == pattern {
+			return "none"
+		}
+	}
+
+	id := fmt.Sprintf("p%v", r.patternID)
+	r.patternID++
+
+	// the tile is rendered in SVG coordinates of the tile, which are transformed to pattern coordinates, to path coordinates and then to SVG coordinates
+	w, h := pattern.Tile.W+pattern.Spacing.X, pattern.Tile.H+pattern.Spacing.Y
+	pm := canvas.Identity.ReflectYAbout(r.height / 2.0).Mul(m).Mul(pattern.M).ReflectYAbout(pattern.Tile.H / 2.0)
+	fmt.Fprintf(r.w, `<pattern id="%v" patternUnits="userSpaceOnUse" width="%v" height="%v" patternTransform="matrix(%v %v %v %v %v %v)">`, id, dec(w), dec(h), dec(pm[0][0]), dec(pm[1][0]), dec(pm[0][1]), dec(pm[1][1]), dec(pm[0][2]), dec(pm[1][2]))
+
+	tile := &SVG{
+		w:          r.w,
+		width:      pattern.Tile.W,
+		height:     pattern.Tile.H,
+		embedFonts: r.embedFonts,
+		fonts:      r.fonts,
+		maskID:     r.maskID,
+		gradientID: r.gradientID,
+		patternID:  r.patternID,
+		imgEnc:     r.imgEnc,
+		patterns:   append(r.patterns[:len(r.patterns):len(r.patterns)], pattern),
+		classes:    []string{},
+	}
+	pattern.Tile.Render(tile)
+	r.maskID, r.gradientID, r.patternID = tile.maskID, tile.gradientID, tile.patternID
+	fmt.Fprintf(r.w, `</pattern>`)
+	return fmt.Sprintf("url(#%v)", id)
+}
+
+// writeGradient writes the definition of a linear or radial gradient for a path with transformation m and returns a reference to it.
+func (r *SVG) writeGradient(paint canvas.Paint, m canvas.Matrix) string {
+	id := fmt.Sprintf("g%v", r.gradientID)
+	r.gradientID++
+
+	var gradient *canvas.Gradient
+	offset := func(t float64) float64 { return t }
+	switch g := paint.(type) {
+	case *canvas.LinearGradient:
+		gradient = &g.Gradient
+		fmt.Fprintf(r.w, `<linearGradient id="%v" gradientUnits="userSpaceOnUse" x1="%v" y1="%v" x2="%v" y2="%v`, id, dec(g.Start.X), dec(g.Start.Y), dec(g.End.X), dec(g.End.Y))
+	case *canvas.RadialGradient:
+		gradient = &g.Gradient
+		fmt.Fprintf(r.w, `<radialGradient id="%v" gradientUnits="userSpaceOnUse" cx="%v" cy="%v" r="%v`, id, dec(g.C1.X), dec(g.C1.Y), dec(g.R1))
+		if !g.C0.Equals(g.C1) {
+			fmt.Fprintf(r.w, `" fx="%v" fy="%v`, dec(g.C0.X), dec(g.C0.Y))
+		}
+		if g.R0 != 0.0 {
+			// concentric circles, see paintSupported, map the stops from between both circles to between the center and the end circle
+			offset = func(t float64) float64 { return (g.R0 + t*(g.R1-g.R0)) / g.R1 }
+		}
+	}
+	if gradient.Spread == canvas.ReflectSpread {
+		fmt.Fprintf(r.w, `" spreadMethod="reflect`)
+	} else if gradient.Spread == canvas.RepeatSpread {
+		fmt.Fprintf(r.w, `" spreadMethod="repeat`)
+	}
+
+	// the gradient is given in gradient coordinates, which are transformed to path coordinates and then to SVG coordinates
+	gm := canvas.Identity.ReflectYAbout(r.height / 2.0).Mul(m).Mul(gradient.M)
+	fmt.Fprintf(r.w, `" gradientTransform="matrix(%v %v %v %v %v %v)">`, dec(gm[0][0]), dec(gm[1][0]), dec(gm[0][1]), dec(gm[1][1]), dec(gm[0][2]), dec(gm[1][2]))
+	for _, stop := range gradient.Stops {
+		col := stop.Color
+		if col.A != 0 && col.A != 255 {
+			col.R = uint8(float64(col.R)*255.0/float64(col.A) + 0.5)
+			col.G = uint8(float64(col.G)*255.0/float64(col.A) + 0.5)
+			col.B = uint8(float64(col.B)*255.0/float64(col.A) + 0.5)
+		}
+		fmt.Fprintf(r.w, `<stop offset="%v" stop-color="%v`, dec(offset(stop.Offset)), canvas.CSSColor(color.RGBA{col.R, col.G, col.B, 255}))
+		if col.A != 255 {
+			fmt.Fprintf(r.w, `" stop-opacity="%v`, dec(float64(col.A)/255.0))
+		}
+		fmt.Fprintf(r.w, `"/>`)
+	}
+	if _, ok := paint.(*canvas.LinearGradient); ok {
+		fmt.Fprintf(r.w, `</linearGradient>`)
+	} else {
+		fmt.Fprintf(r.w, `</radialGradient>`)
+	}
+	return fmt.Sprintf("url(#%v)", id)
+}
+
 func (r *SVG) writeFontStyle(ff, ffMain canvas.FontFace) {
 	boldness := ff.Boldness()
 	differences := 0
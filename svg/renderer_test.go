@@ -1,7 +1,12 @@
 package svg
 
 import (
+	"bytes"
+	"strings"
 	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
 )
 
 func TestSVGText(t *testing.T) {
@@ -30,3 +35,77 @@ func TestSVGText(t *testing.T) {
 	//s := regexp.MustCompile(`base64,.+'`).ReplaceAllString(buf.String(), "base64,'") // remove embedded font
 	//test.String(t, s, `<style>`+"\n"+`@font-face{font-family:'dejavu-serif';src:url('data:font/truetype;base64,');}`+"\n"+`@font-face{font-family:'eb-garamond';src:url('data:font/opentype;base64,');}`+"\n"+`</style><text x="0" y="0" style="font: 12px dejavu-serif"><tspan x="0" y="7.421875" style="font:8px dejavu-serif">dejaVu8</tspan><tspan x="0" y="20.453125" letter-spacing="1" style="font-style:italic;fill:#f00">glyphspacing</tspan><tspan x="0" y="33.725625" style="font:700 6.996px dejavu-serif">dejaVu12sub</tspan><tspan x="0" y="38.5" style="font:700 10px eb-garamond">garamond10</tspan></text><path d="M0 22.703125H91.71875V21.803125H0z" fill="#f00"/>`)
 }
+
+func TestSVGGradient(t *testing.T) {
+	linear := canvas.NewLinearGradient(canvas.Point{X: 0.0, Y: 0.0}, canvas.Point{X: 10.0, Y: 0.0})
+	linear.AddColorStop(0.0, canvas.Red)
+	linear.AddColorStop(1.0, canvas.Transparent)
+	linear.Spread = canvas.ReflectSpread
+
+	buf := &bytes.Buffer{}
+	svg := New(buf, 20.0, 10.0)
+	style := canvas.DefaultStyle
+	style.FillColor = linear.Color()
+	style.FillPaint = linear
+	svg.RenderPath(canvas.Rectangle(10.0, 5.0), style, canvas.Identity.Translate(5.0, 0.0))
+	test.Error(t, svg.Close())
+	test.String(t, buf.String(), `<svg version="1.1" width="20mm" height="10mm" viewBox="0 0 20 10" xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><linearGradient id="g0" gradientUnits="userSpaceOnUse" x1="0" y1="0" x2="10" y2="0" spreadMethod="reflect" gradientTransform="matrix(1 0 0 -1 5 10)"><stop offset="0" stop-color="#f00"/><stop offset="1" stop-color="#000" stop-opacity="0"/></linearGradient><path d="M5 10H15V5H5z" fill="url(#g0)"/></svg>`)
+
+	// concentric radial gradients with a start radius have their stops remapped
+	radial := canvas.NewRadialGradient(canvas.Point{X: 0.0, Y: 0.0}, 2.0, canvas.Point{X: 0.0, Y: 0.0}, 4.0)
+	radial.AddColorStop(0.0, canvas.Red)
+	radial.AddColorStop(1.0, canvas.Blue)
+	buf.Reset()
+	svg = New(buf, 20.0, 10.0)
+	style.FillColor = radial.Color()
+	style.FillPaint = radial
+	svg.RenderPath(canvas.Rectangle(10.0, 5.0), style, canvas.Identity)
+	test.Error(t, svg.Close())
+	test.String(t, buf.String(), `<svg version="1.1" width="20mm" height="10mm" viewBox="0 0 20 10" xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><radialGradient id="g0" gradientUnits="userSpaceOnUse" cx="0" cy="0" r="4" gradientTransform="matrix(1 0 0 -1 0 10)"><stop offset=".5" stop-color="#f00"/><stop offset="1" stop-color="#00f"/></radialGradient><path d="M0 10H10V5H0z" fill="url(#g0)"/></svg>`)
+
+	// other radial gradients with a start radius are rasterized
+	radial.Spread = canvas.RepeatSpread
+	buf.Reset()
+	svg = New(buf, 20.0, 10.0)
+	svg.RenderPath(canvas.Rectangle(10.0, 5.0), style, canvas.Identity)
+	test.That(t, strings.Contains(buf.String(), "<image "), "expected repeated radial gradient to be rasterized")
+	test.That(t, !strings.Contains(buf.String(), " fr="), "unexpected SVG2 attribute")
+
+	// conic gradients are rasterized
+	conic := canvas.NewConicGradient(canvas.Point{X: 0.0, Y: 0.0}, 0.0)
+	conic.AddColorStop(0.0, canvas.Red)
+	conic.AddColorStop(1.0, canvas.Blue)
+	buf.Reset()
+	svg = New(buf, 20.0, 10.0)
+	style.FillColor = conic.Color()
+	style.FillPaint = conic
+	svg.RenderPath(canvas.Rectangle(10.0, 5.0), style, canvas.Identity)
+	test.That(t, strings.Contains(buf.String(), "<image "), "expected conic gradient to be rasterized")
+}
+
+func TestSVGPattern(t *testing.T) {
+	pattern := canvas.NewHatchPattern(canvas.Black, 0.0, 2.0, 1.0)
+	pattern.Spacing = canvas.Point{X: 0.0, Y: 1.0}
+
+	buf := &bytes.Buffer{}
+	svg := New(buf, 20.0, 10.0)
+	style := canvas.DefaultStyle
+	style.FillColor = pattern.Color()
+	style.FillPaint = pattern
+	svg.RenderPath(canvas.Rectangle(10.0, 5.0), style, canvas.Identity)
+	test.Error(t, svg.Close())
+	test.String(t, buf.String(), `<svg version="1.1" width="20mm" height="10mm" viewBox="0 0 20 10" xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><pattern id="p0" patternUnits="userSpaceOnUse" width="2" height="3" patternTransform="matrix(1 0 0 1 0 8)"><path d="M0 1.5H2V.5H0z"/></pattern><path d="M0 10H10V5H0z" fill="url(#p0)"/></svg>`)
+
+	// a pattern that is used in its own tile is not drawn inside the tile
+	tile := canvas.New(2.0, 2.0)
+	recursive := canvas.NewPattern(tile)
+	ctx := canvas.NewContext(tile)
+	ctx.SetFillPaint(recursive)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(1.0, 1.0))
+	buf.Reset()
+	svg = New(buf, 20.0, 10.0)
+	style.FillPaint = recursive
+	svg.RenderPath(canvas.Rectangle(10.0, 5.0), style, canvas.Identity)
+	test.T(t, strings.Count(buf.String(), "<pattern "), 1)
+	test.That(t, strings.Contains(buf.String(), `fill="none"`), buf.String())
+}
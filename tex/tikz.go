@@ -0,0 +1,223 @@
+package tex
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/tdewolff/canvas"
+)
+
+// TikZ is a renderer that writes a tikzpicture environment for LaTeX documents. Paths are written as TikZ paths in millimeters and text is written as text nodes, so that it is typeset by TeX using the document's fonts. Only the font size, weight, slant and small caps of the text are kept, and spacing within a text span may differ from the original.
+type TikZ struct {
+	w             io.Writer
+	width, height float64
+	colors        map[color.RGBA]string
+}
+
+// NewTikZ creates a TikZ renderer (\usepackage{tikz}).
+func NewTikZ(w io.Writer, width, height float64) *TikZ {
+	fmt.Fprintf(w, "\\begin{tikzpicture}[x=1mm,y=1mm]")
+	fmt.Fprintf(w, "\n\\useasboundingbox (0,0) rectangle (%v,%v);", dec(width), dec(height))
+	return &TikZ{
+		w:      w,
+		width:  width,
+		height: height,
+		colors: map[color.RGBA]string{},
+	}
+}
+
+func (r *TikZ) Close() error {
+	_, err := fmt.Fprintf(r.w, "\n\\end{tikzpicture}")
+	return err
+}
+
+func (r *TikZ) Size() (float64, float64) {
+	return r.width, r.height
+}
+
+// getColor returns the name of the color without opacity, defining it when first used.
+func (r *TikZ) getColor(col color.RGBA) string {
+	col.A = 255
+	if col.R == 0 && col.G == 0 && col.B == 0 {
+		return "black"
+	} else if name, ok := r.colors[col]; ok {
+		return name
+	}
+
+	name := fmt.Sprintf("canvasColor%v", len(r.colors))
+	fmt.Fprintf(r.w, "\n\\definecolor{%v}{RGB}{%v,%v,%v}", name, col.R, col.G, col.B)
+	r.colors[col] = name
+	return name
+}
+
+// unpremultiply returns the color with its channels not premultiplied by alpha.
+func unpremultiply(col color.RGBA) color.RGBA {
+	if col.A == 0 || col.A == 255 {
+		return col
+	}
+	A := float64(col.A) / 255.0
+	return color.RGBA{
+		uint8(math.Round(float64(col.R) / A)),
+		uint8(math.Round(float64(col.G) / A)),
+		uint8(math.Round(float64(col.B) / A)),
+		col.A,
+	}
+}
+
+func (r *TikZ) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix) {
+	fill := style.HasFill()
+	stroke := style.HasStroke()
+	if path.Empty() || !fill && !stroke {
+		return
+	}
+	path = path.Transform(m)
+
+	strokeUnsupported := false
+	if _, ok := style.StrokeJoiner.(canvas.ArcsJoiner); ok {
+		strokeUnsupported = true
+	} else if miter, ok := style.StrokeJoiner.(canvas.MiterJoiner); ok {
+		if math.IsNaN(miter.Limit) {
+			strokeUnsupported = true
+		} else if _, ok := miter.GapJoiner.(canvas.BevelJoiner); !ok {
+			strokeUnsupported = true
+		}
+	}
+
+	opts := []string{}
+	if fill {
+		opts = append(opts, r.colorOptions("fill", style.FillColor)...)
+		if style.FillRule == canvas.EvenOdd {
+			opts = append(opts, "even odd rule")
+		}
+	}
+	if stroke && !strokeUnsupported {
+		opts = append(opts, r.colorOptions("draw", style.StrokeColor)...)
+		opts = append(opts, fmt.Sprintf("line width=%vmm", dec(style.StrokeWidth)))
+		if _, ok := style.StrokeCapper.(canvas.RoundCapper); ok {
+			opts = append(opts, "line cap=round")
+		} else if _, ok := style.StrokeCapper.(canvas.SquareCapper); ok {
+			opts = append(opts, "line cap=rect")
+		} else if _, ok := style.StrokeCapper.(canvas.ButtCapper); !ok {
+			panic("TikZ: line cap not support")
+		}
+		if _, ok := style.StrokeJoiner.(canvas.BevelJoiner); ok {
+			opts = append(opts, "line join=bevel")
+		} else if _, ok := style.StrokeJoiner.(canvas.RoundJoiner); ok {
+			opts = append(opts, "line join=round")
+		} else if miter, ok := style.StrokeJoiner.(canvas.MiterJoiner); ok {
+			if limit := miter.Limit * 2.0 / style.StrokeWidth; !canvas.Equal(limit, 10.0) {
+				opts = append(opts, fmt.Sprintf("miter limit=%v", dec(limit)))
+			}
+		}
+		if 0 < len(style.Dashes) {
+			dashes := style.Dashes
+			if len(dashes)%2 == 1 {
+				dashes = append(append([]float64{}, dashes...), dashes...)
+			}
+			pattern := &strings.Builder{}
+			for i, dash := range dashes {
+				if i%2 == 0 {
+					fmt.Fprintf(pattern, " on %vmm", dec(dash))
+				} else {
+					fmt.Fprintf(pattern, " off %vmm", dec(dash))
+				}
+			}
+			opts = append(opts, "dash pattern="+pattern.String()[1:])
+			if style.DashOffset != 0.0 {
+				opts = append(opts, fmt.Sprintf("dash phase=%vmm", dec(style.DashOffset)))
+			}
+		}
+	}
+
+	if 0 < len(opts) {
+		r.writePath(path, opts)
+	}
+
+	if stroke && strokeUnsupported {
+		// stroke settings unsupported by TikZ, draw stroke explicitly
+		if 0 < len(style.Dashes) {
+			path = path.Dash(style.DashOffset, style.Dashes...)
+		}
+		path = path.Stroke(style.StrokeWidth, style.StrokeCapper, style.StrokeJoiner)
+		r.writePath(path, r.colorOptions("fill", style.StrokeColor))
+	}
+}
+
+// colorOptions returns the color and opacity options for the given key, such as fill, draw or text.
+func (r *TikZ) colorOptions(key string, col color.RGBA) []string {
+	col = unpremultiply(col)
+	opts := []string{fmt.Sprintf("%v=%v", key, r.getColor(col))}
+	if col.A != 255 {
+		opts = append(opts, fmt.Sprintf("%v opacity=%v", key, dec(float64(col.A)/255.0)))
+	}
+	return opts
+}
+
+func (r *TikZ) writePath(path *canvas.Path, opts []string) {
+	fmt.Fprintf(r.w, "\n\\path[%v]", strings.Join(opts, ","))
+	path.ReplaceArcs().Iterate(func(start, end canvas.Point) {
+		fmt.Fprintf(r.w, " (%v,%v)", dec(end.X), dec(end.Y))
+	}, func(start, end canvas.Point) {
+		fmt.Fprintf(r.w, " -- (%v,%v)", dec(end.X), dec(end.Y))
+	}, func(start, cp, end canvas.Point) {
+		// TikZ has no quadratic Béziers
+		cp1 := start.Interpolate(cp, 2.0/3.0)
+		cp2 := end.Interpolate(cp, 2.0/3.0)
+		fmt.Fprintf(r.w, " .. controls (%v,%v) and (%v,%v) .. (%v,%v)", dec(cp1.X), dec(cp1.Y), dec(cp2.X), dec(cp2.Y), dec(end.X), dec(end.Y))
+	}, func(start, cp1, cp2, end canvas.Point) {
+		fmt.Fprintf(r.w, " .. controls (%v,%v) and (%v,%v) .. (%v,%v)", dec(cp1.X), dec(cp1.Y), dec(cp2.X), dec(cp2.Y), dec(end.X), dec(end.Y))
+	}, func(start canvas.Point, rx, ry, rot float64, large, sweep bool, end canvas.Point) {
+		// arcs have been replaced
+	}, func(start, end canvas.Point) {
+		fmt.Fprintf(r.w, " -- cycle")
+	})
+	fmt.Fprintf(r.w, ";")
+}
+
+func (r *TikZ) RenderText(text *canvas.Text, m canvas.Matrix) {
+	if text.Empty() {
+		return
+	}
+
+	scope := !m.IsTranslation()
+	x0, y0 := m.Pos()
+	if scope {
+		fmt.Fprintf(r.w, "\n\\begin{scope}[cm={%v,%v,%v,%v,(%v,%v)}]", dec(m[0][0]), dec(m[1][0]), dec(m[0][1]), dec(m[1][1]), dec(x0), dec(y0))
+		x0, y0 = 0.0, 0.0
+	}
+	text.WalkSpans(func(y, dx float64, span canvas.TextSpan) {
+		opts := []string{"anchor=base west", "inner sep=0", "outer sep=0"}
+		if scope {
+			opts = append(opts, "transform shape")
+		}
+		if span.Face.Color != canvas.Black {
+			opts = append(opts, r.colorOptions("text", span.Face.Color)...)
+		}
+
+		size := span.Face.Size * span.Face.Scale * ptPerMm
+		font := fmt.Sprintf("\\fontsize{%vbp}{%vbp}\\selectfont", dec(size), dec(size*1.2))
+		if 600 <= span.Face.Boldness() {
+			font += "\\bfseries"
+		}
+		if span.Face.Style&canvas.FontItalic != 0 {
+			font += "\\itshape"
+		}
+		if span.Face.Variant&canvas.FontSmallcaps != 0 {
+			font += "\\scshape"
+		}
+		opts = append(opts, "font="+font)
+		fmt.Fprintf(r.w, "\n\\node[%v] at (%v,%v) {%v};", strings.Join(opts, ","), dec(x0+dx), dec(y0+y+span.Face.Voffset), escape(span.Text))
+	})
+	if scope {
+		fmt.Fprintf(r.w, "\n\\end{scope}")
+	}
+	text.RenderDecoration(r, m)
+}
+
+func (r *TikZ) RenderImage(img image.Image, m canvas.Matrix) {
+	// TODO: (TikZ) write image
+}
@@ -0,0 +1,62 @@
+package tex
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func TestTikZPath(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tikz := NewTikZ(buf, 100, 50)
+	tikz.RenderPath(canvas.MustParseSVG("M0 0L10 0Q20 0 20 10z"), canvas.DefaultStyle, canvas.Identity.Translate(5, 5))
+
+	style := canvas.DefaultStyle
+	style.FillColor = color.RGBA{128, 0, 0, 128}
+	style.FillRule = canvas.EvenOdd
+	style.StrokeColor = canvas.Blue
+	style.StrokeWidth = 0.5
+	style.StrokeCapper = canvas.RoundCap
+	style.StrokeJoiner = canvas.BevelJoin
+	style.Dashes = []float64{1.0}
+	tikz.RenderPath(canvas.MustParseSVG("M0 0L10 0"), style, canvas.Identity)
+	test.Error(t, tikz.Close())
+	test.String(t, buf.String(), `\begin{tikzpicture}[x=1mm,y=1mm]
+\useasboundingbox (0,0) rectangle (100,50);
+\path[fill=black] (5,5) -- (15,5) .. controls (21.666667,5) and (25,8.3333333) .. (25,15) -- cycle;
+\definecolor{canvasColor0}{RGB}{255,0,0}
+\definecolor{canvasColor1}{RGB}{0,0,255}
+\path[fill=canvasColor0,fill opacity=.50196078,even odd rule,draw=canvasColor1,line width=.5mm,line cap=round,line join=bevel,dash pattern=on 1mm off 1mm] (0,0) -- (10,0);
+\end{tikzpicture}`)
+
+	// odd dash arrays are repeated without writing into the style's backing array
+	dashes := make([]float64, 1, 2)
+	dashes[0] = 1.0
+	style.Dashes = dashes
+	tikz.RenderPath(canvas.MustParseSVG("M0 0L10 0"), style, canvas.Identity)
+	test.T(t, dashes[:2], []float64{1.0, 0.0})
+}
+
+func TestTikZText(t *testing.T) {
+	family := canvas.NewFontFamily("dejavu-serif")
+	test.Error(t, family.LoadFontFile("../font/DejaVuSerif.ttf", canvas.FontRegular))
+	face := family.Face(12.0, canvas.Red, canvas.FontRegular, canvas.FontNormal)
+	text := canvas.NewTextLine(face, "50% & $5_{x}", canvas.Left)
+
+	buf := &bytes.Buffer{}
+	tikz := NewTikZ(buf, 100, 50)
+	tikz.RenderText(text, canvas.Identity.Translate(10, 20))
+	tikz.RenderText(text, canvas.Identity.Rotate(90))
+	test.Error(t, tikz.Close())
+	test.String(t, buf.String(), `\begin{tikzpicture}[x=1mm,y=1mm]
+\useasboundingbox (0,0) rectangle (100,50);
+\definecolor{canvasColor0}{RGB}{255,0,0}
+\node[anchor=base west,inner sep=0,outer sep=0,text=canvasColor0,font=\fontsize{12bp}{14.4bp}\selectfont] at (10,20) {50\% \& \$5\_\{x\}};
+\begin{scope}[cm={0,1,-1,0,(0,0)}]
+\node[anchor=base west,inner sep=0,outer sep=0,transform shape,text=canvasColor0,font=\fontsize{12bp}{14.4bp}\selectfont] at (0,0) {50\% \& \$5\_\{x\}};
+\end{scope}
+\end{tikzpicture}`)
+}
@@ -33,3 +33,24 @@ func (f dec) String() string {
 	}
 	return s
 }
+
+const ptPerMm = 72.0 / 25.4
+
+// escape escapes the characters that have a special meaning in TeX.
+func escape(s string) string {
+	return texReplacer.Replace(s)
+}
+
+var texReplacer = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`$`, `\$`,
+	`&`, `\&`,
+	`#`, `\#`,
+	`%`, `\%`,
+	`_`, `\_`,
+	`^`, `\textasciicircum{}`,
+	`~`, `\textasciitilde{}`,
+	"\n", " ",
+)
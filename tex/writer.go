@@ -13,3 +13,10 @@ func Writer(w io.Writer, c *canvas.Canvas) error {
 	c.Render(tex)
 	return tex.Close()
 }
+
+// TikZWriter writes the canvas as a TeX file using TikZ (\usepackage{tikz}), where text is typeset by TeX.
+func TikZWriter(w io.Writer, c *canvas.Canvas) error {
+	tikz := NewTikZ(w, c.W, c.H)
+	c.Render(tikz)
+	return tikz.Close()
+}
@@ -486,6 +486,7 @@ func (t *Text) MostCommonFontFace() FontFace {
 	styles := map[FontStyle]int{}
 	variants := map[FontVariant]int{}
 	colors := map[color.RGBA]int{}
+	originals := map[color.RGBA]color.Color{} // CMYK colors
 	for _, line := range t.lines {
 		for _, span := range line.spans {
 			families[span.Face.family]++
@@ -493,6 +494,9 @@ func (t *Text) MostCommonFontFace() FontFace {
 			styles[span.Face.Style]++
 			variants[span.Face.Variant]++
 			colors[span.Face.Color]++
+			if p, ok := span.Face.Paint.(ColorPaint); ok {
+				originals[span.Face.Color] = p.C
+			}
 		}
 	}
 	if len(families) == 0 {
@@ -525,28 +529,41 @@ func (t *Text) MostCommonFontFace() FontFace {
 			col = key
 		}
 	}
+	if original, ok := originals[col]; ok {
+		return family.Face(size*ptPerMm, original, style, variant)
+	}
 	return family.Face(size*ptPerMm, col, style, variant)
 }
 
 // ToPaths makes a path out of the text, with x,y the top-left point of the rectangle that fits the text (ie. y is not the text base)
 func (t *Text) ToPaths() ([]*Path, []color.RGBA) {
+	paths, faces := t.toPaths()
+	colors := make([]color.RGBA, len(faces))
+	for i, face := range faces {
+		colors[i] = face.Color
+	}
+	return paths, colors
+}
+
+// toPaths returns the paths of the text as ToPaths does, together with the font face of each path.
+func (t *Text) toPaths() ([]*Path, []FontFace) {
 	paths := []*Path{}
-	colors := []color.RGBA{}
+	faces := []FontFace{}
 	for _, line := range t.lines {
 		for _, span := range line.spans {
-			p, _, col := span.ToPath(span.width)
+			p, _, _ := span.ToPath(span.width)
 			p = p.Translate(span.dx, line.y)
 			paths = append(paths, p)
-			colors = append(colors, col)
+			faces = append(faces, span.Face)
 		}
 		for _, deco := range line.decos {
 			p := deco.face.Decorate(deco.x1 - deco.x0)
 			p = p.Translate(deco.x0, line.y)
 			paths = append(paths, p)
-			colors = append(colors, deco.face.Color)
+			faces = append(faces, deco.face)
 		}
 	}
-	return paths, colors
+	return paths, faces
 }
 
 // RenderDecoration renders the text decorations using the RenderPath method of the Renderer.
@@ -559,6 +576,7 @@ func (t *Text) RenderDecoration(r Renderer, m Matrix) {
 			p := deco.face.Decorate(deco.x1 - deco.x0)
 			p = p.Transform(Identity.Mul(m).Translate(deco.x0, line.y+deco.face.Voffset))
 			style.FillColor = deco.face.Color
+			style.FillPaint = deco.face.Paint
 			r.RenderPath(p, style, Identity)
 		}
 	}
@@ -0,0 +1,744 @@
+// Package trace converts raster images to paths, similar to potrace. Bi-level images such as scanned signatures, logos or masks are traced by Trace, and posterized images with a few colors by TraceColors.
+//
+// Tracing happens in three steps. First the boundaries between foreground and background pixels are decomposed into closed polygons, removing speckles. These are then converted to the optimal polygons that approximate the pixel boundaries, and finally their vertices are either kept as corners or smoothed into cubic Béziers depending on how sharp they are.
+package trace
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/tdewolff/canvas"
+)
+
+// Options are the options for tracing.
+type Options struct {
+	// Threshold is the luminance between 0 and 1 below which pixels are foreground, after compositing transparent pixels onto white. If Invert is true, pixels above the threshold are foreground.
+	Threshold float64
+	Invert    bool
+
+	// TurdSize is the area in pixels up to which shapes and holes are removed as speckles.
+	TurdSize int
+
+	// AlphaMax is the corner threshold, smaller values give more corners and zero gives a polygon, while values above 4/3 give no corners.
+	AlphaMax float64
+}
+
+// DefaultOptions are the default options for tracing, similar to potrace.
+var DefaultOptions = Options{
+	Threshold: 0.5,
+	Invert:    false,
+	TurdSize:  2,
+	AlphaMax:  1.0,
+}
+
+// Trace traces the foreground pixels of an image and returns the outlines as a path that is filled with the non-zero fill rule. The path is in pixel units with the origin at the bottom-left of the image and the y-axis pointing up, scale it by one over the resolution to obtain millimeters. If opts is nil, DefaultOptions are used.
+func Trace(img image.Image, opts *Options) *canvas.Path {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	bounds := img.Bounds()
+	bm := newBitmap(bounds.Dx(), bounds.Dy())
+	for y := 0; y < bm.h; y++ {
+		for x := 0; x < bm.w; x++ {
+			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			// composite onto white, colors are premultiplied
+			white := float64(0xffff - a)
+			lum := (0.299*(float64(r)+white) + 0.587*(float64(g)+white) + 0.114*(float64(b)+white)) / 0xffff
+			bm.set(x, y, (lum < opts.Threshold) != opts.Invert)
+		}
+	}
+	return trace(bm, opts)
+}
+
+// TraceColors traces each color of an image with few colors, such as a posterized image, and returns a path and color per layer. The layers are ordered by area from largest to smallest and are stacked so that each layer also covers the areas of the layers that follow, this prevents gaps between adjacent layers when drawn in order. Fully transparent pixels are not traced. The image should have a limited number of colors, see Posterize. If opts is nil, DefaultOptions are used while Threshold and Invert are ignored.
+func TraceColors(img image.Image, opts *Options) ([]*canvas.Path, []color.RGBA) {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	indices := map[color.RGBA]int{}
+	colors := []color.RGBA{}
+	areas := []int{}
+	pixels := make([]int, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			col := color.RGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.RGBA)
+			if col.A == 0 {
+				pixels[y*w+x] = -1
+				continue
+			}
+			i, ok := indices[col]
+			if !ok {
+				i = len(colors)
+				indices[col] = i
+				colors = append(colors, col)
+				areas = append(areas, 0)
+			}
+			areas[i]++
+			pixels[y*w+x] = i
+		}
+	}
+
+	// order colors from largest to smallest area
+	order := make([]int, len(colors))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return areas[order[j]] < areas[order[i]]
+	})
+	rank := make([]int, len(colors))
+	for r, i := range order {
+		rank[i] = r
+	}
+
+	paths := make([]*canvas.Path, 0, len(colors))
+	layerColors := make([]color.RGBA, 0, len(colors))
+	for r, i := range order {
+		bm := newBitmap(w, h)
+		for j, k := range pixels {
+			bm.pix[j] = k != -1 && r <= rank[k]
+		}
+		if path := trace(bm, opts); !path.Empty() {
+			paths = append(paths, path)
+			layerColors = append(layerColors, colors[i])
+		}
+	}
+	return paths, layerColors
+}
+
+// Posterize returns a paletted image where each color channel is reduced to the given number of levels, which should be at least two. Pixels with less than half opacity become transparent.
+func Posterize(img image.Image, levels int) *image.Paletted {
+	if levels < 2 {
+		levels = 2
+	}
+	quantize := func(c uint32) uint8 {
+		level := (int(c)*(levels-1) + 0x7fff) / 0xffff
+		return uint8(level * 255 / (levels - 1))
+	}
+
+	bounds := img.Bounds()
+	palette := color.Palette{color.Transparent}
+	indices := map[color.Color]uint8{}
+	dst := image.NewPaletted(image.Rect(0, 0, bounds.Dx(), bounds.Dy()), nil)
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			col := color.NRGBA64Model.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA64)
+			if col.A < 0x8000 {
+				continue
+			}
+			c := color.RGBA{quantize(uint32(col.R)), quantize(uint32(col.G)), quantize(uint32(col.B)), 255}
+			i, ok := indices[c]
+			if !ok {
+				if len(palette) == 256 {
+					i = uint8(palette.Index(c))
+				} else {
+					i = uint8(len(palette))
+					indices[c] = i
+					palette = append(palette, c)
+				}
+			}
+			dst.Pix[y*dst.Stride+x] = i
+		}
+	}
+	dst.Palette = palette
+	return dst
+}
+
+////////////////////////////////////////////////////////////////
+
+// bitmap is a bi-level image where pixels outside the bounds are background.
+type bitmap struct {
+	w, h int
+	pix  []bool
+}
+
+func newBitmap(w, h int) *bitmap {
+	return &bitmap{w, h, make([]bool, w*h)}
+}
+
+func (bm *bitmap) get(x, y int) bool {
+	return 0 <= x && x < bm.w && 0 <= y && y < bm.h && bm.pix[y*bm.w+x]
+}
+
+func (bm *bitmap) set(x, y int, v bool) {
+	bm.pix[y*bm.w+x] = v
+}
+
+func (bm *bitmap) clone() *bitmap {
+	pix := make([]bool, len(bm.pix))
+	copy(pix, bm.pix)
+	return &bitmap{bm.w, bm.h, pix}
+}
+
+// point is a corner between pixels, with the y-axis pointing down.
+type point struct {
+	x, y int
+}
+
+func trace(bm *bitmap, opts *Options) *canvas.Path {
+	path := &canvas.Path{}
+	for _, corners := range decompose(bm, opts.TurdSize) {
+		vertices := polygon(corners, bm.h)
+		if len(vertices) < 3 {
+			continue
+		}
+		path = path.Append(smooth(vertices, opts.AlphaMax))
+	}
+	return path
+}
+
+// decompose returns the boundaries of the foreground as closed polygons of the corners where the boundary changes direction. Outer boundaries are counter clockwise and inner boundaries (holes) are clockwise when the y-axis points up. Boundaries enclosing an area of at most turdSize pixels are removed together with their contents.
+func decompose(bm *bitmap, turdSize int) [][]point {
+	orig := bm
+	bm = bm.clone()
+
+	polygons := [][]point{}
+	for y := 0; y < bm.h; y++ {
+		for x := 0; x < bm.w; x++ {
+			if !bm.pix[y*bm.w+x] {
+				continue
+			}
+
+			// trace the boundary with the foreground on the right, starting at the top-left corner of the first pixel found. Its top and left neighbours are background, otherwise they would have been found before.
+			start := point{x, y}
+			p, dx, dy := start, 1, 0
+			polygon := []point{}
+			area := 0
+			for {
+				q := point{p.x + dx, p.y + dy}
+				area += p.x*q.y - q.x*p.y
+				p = q
+				if p == start {
+					break
+				}
+
+				right := bm.get(p.x+(dx-dy-1)/2, p.y+(dy+dx-1)/2)
+				left := bm.get(p.x+(dx+dy-1)/2, p.y+(dy-dx-1)/2)
+				if left {
+					// turn left, diagonally touching pixels are connected
+					polygon = append(polygon, p)
+					dx, dy = dy, -dx
+				} else if !right {
+					// turn right
+					polygon = append(polygon, p)
+					dx, dy = -dy, dx
+				}
+			}
+			polygon = append(polygon, start)
+
+			// invert the interior so that it will not be found again and holes become foreground
+			for i := range polygon {
+				p, q := polygon[i], polygon[(i+1)%len(polygon)]
+				if p.x != q.x {
+					continue
+				}
+				y0, y1 := p.y, q.y
+				if y1 < y0 {
+					y0, y1 = y1, y0
+				}
+				x0, x1 := p.x, x
+				if x1 < x0 {
+					x0, x1 = x1, x0
+				}
+				for y := y0; y < y1; y++ {
+					for x := x0; x < x1; x++ {
+						bm.pix[y*bm.w+x] = !bm.pix[y*bm.w+x]
+					}
+				}
+			}
+
+			if turdSize < abs(area)/2 {
+				if !orig.pix[y*bm.w+x] {
+					// holes are traced with the hole on the right, reverse to get the opposite orientation
+					for i, j := 0, len(polygon)-1; i < j; i, j = i+1, j-1 {
+						polygon[i], polygon[j] = polygon[j], polygon[i]
+					}
+				}
+				polygons = append(polygons, polygon)
+			}
+		}
+	}
+	return polygons
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// polygon returns the optimal polygon of a boundary as defined by potrace. The boundary is expanded to all pixel corners along it, and is split into the fewest straight segments, where a segment is straight if it passes within half a pixel of all corners between its endpoints. Among those with the fewest segments, the polygon that best fits the corners is chosen. Its vertices are then moved to where the lines that best fit the corners of adjacent segments meet, staying within half a pixel of the original vertex. The y-axis is flipped so that it points up.
+func polygon(corners []point, h int) []canvas.Point {
+	pt := []point{}
+	for i, p := range corners {
+		q := corners[(i+1)%len(corners)]
+		dx, dy := sign(q.x-p.x), sign(q.y-p.y)
+		for x, y := p.x, p.y; x != q.x || y != q.y; x, y = x+dx, y+dy {
+			pt = append(pt, point{x, h - y})
+		}
+	}
+	n := len(pt)
+	if n < 4 {
+		return nil
+	}
+
+	sums := calcSums(pt)
+	lon := calcLon(pt)
+	po := bestPolygon(pt, sums, lon)
+	return adjustVertices(pt, sums, po)
+}
+
+// sum are the cumulative sums of the coordinates relative to the first point, which are used to fit lines.
+type sum struct {
+	x, y, x2, xy, y2 float64
+}
+
+func calcSums(pt []point) []sum {
+	sums := make([]sum, len(pt)+1)
+	for i, p := range pt {
+		x, y := float64(p.x-pt[0].x), float64(p.y-pt[0].y)
+		sums[i+1] = sum{sums[i].x + x, sums[i].y + y, sums[i].x2 + x*x, sums[i].xy + x*y, sums[i].y2 + y*y}
+	}
+	return sums
+}
+
+// calcLon returns for each point the furthest point such that a straight line can be drawn between them.
+func calcLon(pt []point) []int {
+	n := len(pt)
+	nc := make([]int, n)
+	pivk := make([]int, n)
+	lon := make([]int, n)
+
+	// for each point the furthest next point that is connected by a horizontal or vertical line
+	k := 0
+	for i := n - 1; 0 <= i; i-- {
+		if pt[i].x != pt[k].x && pt[i].y != pt[k].y {
+			k = i + 1
+		}
+		nc[i] = k
+	}
+
+	// for each point i the furthest point k such that all points in between lie on a straight line from i to k
+	for i := n - 1; 0 <= i; i-- {
+		ct := [4]int{}
+		next := pt[mod(i+1, n)]
+		ct[(3+3*(next.x-pt[i].x)+(next.y-pt[i].y))/2]++
+
+		constraint := [2]point{}
+		k, k1 := nc[i], i
+		found := false
+		for {
+			ct[(3+3*sign(pt[k].x-pt[k1].x)+sign(pt[k].y-pt[k1].y))/2]++
+			if ct[0] != 0 && ct[1] != 0 && ct[2] != 0 && ct[3] != 0 {
+				// all four directions occurred
+				pivk[i] = k1
+				found = true
+				break
+			}
+
+			cur := point{pt[k].x - pt[i].x, pt[k].y - pt[i].y}
+			if xprod(constraint[0], cur) < 0 || 0 < xprod(constraint[1], cur) {
+				break
+			}
+			if 1 < abs(cur.x) || 1 < abs(cur.y) {
+				off := cur
+				if 0 <= cur.y && (0 < cur.y || cur.x < 0) {
+					off.x++
+				} else {
+					off.x--
+				}
+				if cur.x <= 0 && (cur.x < 0 || cur.y < 0) {
+					off.y++
+				} else {
+					off.y--
+				}
+				if 0 <= xprod(constraint[0], off) {
+					constraint[0] = off
+				}
+
+				off = cur
+				if cur.y <= 0 && (cur.y < 0 || cur.x < 0) {
+					off.x++
+				} else {
+					off.x--
+				}
+				if 0 <= cur.x && (0 < cur.x || cur.y < 0) {
+					off.y++
+				} else {
+					off.y--
+				}
+				if xprod(constraint[1], off) <= 0 {
+					constraint[1] = off
+				}
+			}
+			k1 = k
+			k = nc[k1]
+			if !cyclic(k, i, k1) {
+				break
+			}
+		}
+		if found {
+			continue
+		}
+
+		// k1 was the last corner that satisfied the constraints and k the first that violated them, find the last point in between that satisfies them
+		dk := point{sign(pt[k].x - pt[k1].x), sign(pt[k].y - pt[k1].y)}
+		cur := point{pt[k1].x - pt[i].x, pt[k1].y - pt[i].y}
+		a, b := xprod(constraint[0], cur), xprod(constraint[0], dk)
+		c, d := xprod(constraint[1], cur), xprod(constraint[1], dk)
+		j := math.MaxInt32
+		if b < 0 {
+			j = floordiv(a, -b)
+		}
+		if 0 < d {
+			if jd := floordiv(-c, d); jd < j {
+				j = jd
+			}
+		}
+		pivk[i] = mod(k1+j, n)
+	}
+
+	j := pivk[n-1]
+	lon[n-1] = j
+	for i := n - 2; 0 <= i; i-- {
+		if cyclic(i+1, pivk[i], j) {
+			j = pivk[i]
+		}
+		lon[i] = j
+	}
+	for i := n - 1; 0 <= i && cyclic(mod(i+1, n), j, lon[i]); i-- {
+		lon[i] = j
+	}
+	return lon
+}
+
+// penalty returns how badly the straight line from i to j fits the points in between.
+func penalty(pt []point, sums []sum, i, j int) float64 {
+	n := len(pt)
+	var s sum
+	var k float64
+	if j < n {
+		s = sum{sums[j+1].x - sums[i].x, sums[j+1].y - sums[i].y, sums[j+1].x2 - sums[i].x2, sums[j+1].xy - sums[i].xy, sums[j+1].y2 - sums[i].y2}
+		k = float64(j + 1 - i)
+	} else {
+		j -= n
+		s = sum{sums[j+1].x - sums[i].x + sums[n].x, sums[j+1].y - sums[i].y + sums[n].y, sums[j+1].x2 - sums[i].x2 + sums[n].x2, sums[j+1].xy - sums[i].xy + sums[n].xy, sums[j+1].y2 - sums[i].y2 + sums[n].y2}
+		k = float64(j + 1 - i + n)
+	}
+
+	px := float64(pt[i].x+pt[j].x)/2.0 - float64(pt[0].x)
+	py := float64(pt[i].y+pt[j].y)/2.0 - float64(pt[0].y)
+	ey := float64(pt[j].x - pt[i].x)
+	ex := -float64(pt[j].y - pt[i].y)
+
+	a := (s.x2-2.0*s.x*px)/k + px*px
+	b := (s.xy-s.x*py-s.y*px)/k + px*py
+	c := (s.y2-2.0*s.y*py)/k + py*py
+	return math.Sqrt(math.Max(0.0, ex*ex*a+2.0*ex*ey*b+ey*ey*c))
+}
+
+// bestPolygon returns the indices of the polygon vertices with the fewest segments and the lowest penalty. The first point is always a vertex.
+func bestPolygon(pt []point, sums []sum, lon []int) []int {
+	n := len(pt)
+	pen := make([]float64, n+1)
+	prev := make([]int, n+1)
+	clip0 := make([]int, n)
+	clip1 := make([]int, n+1)
+	seg0 := make([]int, n+1)
+	seg1 := make([]int, n+1)
+
+	for i := 0; i < n; i++ {
+		c := mod(lon[mod(i-1, n)]-1, n)
+		if c == i {
+			c = mod(i+1, n)
+		}
+		if c < i {
+			clip0[i] = n
+		} else {
+			clip0[i] = c
+		}
+	}
+
+	j := 1
+	for i := 0; i < n; i++ {
+		for j <= clip0[i] {
+			clip1[j] = i
+			j++
+		}
+	}
+
+	// seg0[j] is the furthest point from the start with j segments, seg1[j] is the furthest point from the end with m-j segments
+	i := 0
+	for j = 0; i < n; j++ {
+		seg0[j] = i
+		i = clip0[i]
+	}
+	seg0[j] = n
+	m := j
+
+	i = n
+	for j = m; 0 < j; j-- {
+		seg1[j] = i
+		i = clip1[i]
+	}
+	seg1[0] = 0
+
+	for j = 1; j <= m; j++ {
+		for i = seg1[j]; i <= seg0[j]; i++ {
+			best := -1.0
+			for k := seg0[j-1]; clip1[i] <= k; k-- {
+				if p := penalty(pt, sums, k, i) + pen[k]; best < 0.0 || p < best {
+					prev[i] = k
+					best = p
+				}
+			}
+			pen[i] = best
+		}
+	}
+
+	po := make([]int, m)
+	for i, j = n, m-1; 0 < i && 0 <= j; j-- {
+		i = prev[i]
+		po[j] = i
+	}
+	return po
+}
+
+// adjustVertices moves each vertex to the point within half a pixel that minimizes the squared distance to the lines that best fit the adjacent segments.
+func adjustVertices(pt []point, sums []sum, po []int) []canvas.Point {
+	n, m := len(pt), len(po)
+	x0, y0 := float64(pt[0].x), float64(pt[0].y)
+
+	// quadratic forms of the distance to the best fitting line of each segment
+	q := make([][3][3]float64, m)
+	for i := 0; i < m; i++ {
+		j := mod(po[mod(i+1, m)]-po[i], n) + po[i]
+		ctr, dir := pointSlope(pt, sums, po[i], j)
+		if d := dir.X*dir.X + dir.Y*dir.Y; d != 0.0 {
+			v := [3]float64{dir.Y, -dir.X, dir.X*ctr.Y - dir.Y*ctr.X}
+			for l := 0; l < 3; l++ {
+				for k := 0; k < 3; k++ {
+					q[i][l][k] = v[l] * v[k] / d
+				}
+			}
+		}
+	}
+
+	vertices := make([]canvas.Point, m)
+	for i := 0; i < m; i++ {
+		s := canvas.Point{X: float64(pt[po[i]].x) - x0, Y: float64(pt[po[i]].y) - y0}
+		j := mod(i-1, m)
+		var Q [3][3]float64
+		for l := 0; l < 3; l++ {
+			for k := 0; k < 3; k++ {
+				Q[l][k] = q[j][l][k] + q[i][l][k]
+			}
+		}
+
+		var w canvas.Point
+		for {
+			if det := Q[0][0]*Q[1][1] - Q[0][1]*Q[1][0]; det != 0.0 {
+				w.X = (-Q[0][2]*Q[1][1] + Q[1][2]*Q[0][1]) / det
+				w.Y = (Q[0][2]*Q[1][0] - Q[1][2]*Q[0][0]) / det
+				break
+			}
+
+			// lines are parallel, add an orthogonal line through the vertex
+			var v [3]float64
+			if Q[1][1] < Q[0][0] {
+				v[0], v[1] = -Q[0][1], Q[0][0]
+			} else if Q[1][1] != 0.0 {
+				v[0], v[1] = -Q[1][1], Q[1][0]
+			} else {
+				v[0], v[1] = 1.0, 0.0
+			}
+			d := v[0]*v[0] + v[1]*v[1]
+			v[2] = -v[1]*s.Y - v[0]*s.X
+			for l := 0; l < 3; l++ {
+				for k := 0; k < 3; k++ {
+					Q[l][k] += v[l] * v[k] / d
+				}
+			}
+		}
+
+		if math.Abs(w.X-s.X) <= 0.5 && math.Abs(w.Y-s.Y) <= 0.5 {
+			vertices[i] = canvas.Point{X: w.X + x0, Y: w.Y + y0}
+			continue
+		}
+
+		// the minimum is not within the unit square, minimize on its boundary
+		min, best := quadform(Q, s), s
+		if Q[0][0] != 0.0 {
+			for z := 0; z < 2; z++ {
+				w.Y = s.Y - 0.5 + float64(z)
+				w.X = -(Q[0][1]*w.Y + Q[0][2]) / Q[0][0]
+				if cand := quadform(Q, w); math.Abs(w.X-s.X) <= 0.5 && cand < min {
+					min, best = cand, w
+				}
+			}
+		}
+		if Q[1][1] != 0.0 {
+			for z := 0; z < 2; z++ {
+				w.X = s.X - 0.5 + float64(z)
+				w.Y = -(Q[1][0]*w.X + Q[1][2]) / Q[1][1]
+				if cand := quadform(Q, w); math.Abs(w.Y-s.Y) <= 0.5 && cand < min {
+					min, best = cand, w
+				}
+			}
+		}
+		for l := 0; l < 2; l++ {
+			for k := 0; k < 2; k++ {
+				w = canvas.Point{X: s.X - 0.5 + float64(l), Y: s.Y - 0.5 + float64(k)}
+				if cand := quadform(Q, w); cand < min {
+					min, best = cand, w
+				}
+			}
+		}
+		vertices[i] = canvas.Point{X: best.X + x0, Y: best.Y + y0}
+	}
+	return vertices
+}
+
+// pointSlope returns the center and direction of the line that best fits the points from i to j, relative to the first point.
+func pointSlope(pt []point, sums []sum, i, j int) (canvas.Point, canvas.Point) {
+	n := len(pt)
+	r := 0 // rotations from i to j
+	for n <= j {
+		j -= n
+		r++
+	}
+	for n <= i {
+		i -= n
+		r--
+	}
+	for j < 0 {
+		j += n
+		r--
+	}
+	for i < 0 {
+		i += n
+		r++
+	}
+
+	R := float64(r)
+	x := sums[j+1].x - sums[i].x + R*sums[n].x
+	y := sums[j+1].y - sums[i].y + R*sums[n].y
+	x2 := sums[j+1].x2 - sums[i].x2 + R*sums[n].x2
+	xy := sums[j+1].xy - sums[i].xy + R*sums[n].xy
+	y2 := sums[j+1].y2 - sums[i].y2 + R*sums[n].y2
+	k := float64(j + 1 - i + r*n)
+	ctr := canvas.Point{X: x / k, Y: y / k}
+
+	a := (x2 - x*x/k) / k
+	b := (xy - x*y/k) / k
+	c := (y2 - y*y/k) / k
+	lambda := (a + c + math.Sqrt((a-c)*(a-c)+4.0*b*b)) / 2.0 // larger eigenvalue
+
+	// eigenvector of the larger eigenvalue
+	a -= lambda
+	c -= lambda
+	dir := canvas.Point{}
+	if math.Abs(c) <= math.Abs(a) {
+		if l := math.Sqrt(a*a + b*b); l != 0.0 {
+			dir = canvas.Point{X: -b / l, Y: a / l}
+		}
+	} else if l := math.Sqrt(c*c + b*b); l != 0.0 {
+		dir = canvas.Point{X: -c / l, Y: b / l}
+	}
+	return ctr, dir
+}
+
+func quadform(Q [3][3]float64, w canvas.Point) float64 {
+	v := [3]float64{w.X, w.Y, 1.0}
+	sum := 0.0
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			sum += v[i] * Q[i][j] * v[j]
+		}
+	}
+	return sum
+}
+
+func xprod(p, q point) int {
+	return p.x*q.y - p.y*q.x
+}
+
+// cyclic returns true if a <= b < c in a cyclic sense.
+func cyclic(a, b, c int) bool {
+	if a <= c {
+		return a <= b && b < c
+	}
+	return a <= b || b < c
+}
+
+func mod(a, n int) int {
+	if a >= n {
+		return a % n
+	} else if 0 <= a {
+		return a
+	}
+	return n - 1 - (-1-a)%n
+}
+
+func floordiv(a, n int) int {
+	if 0 <= a {
+		return a / n
+	}
+	return -1 - (-1-a)/n
+}
+
+// smooth returns a closed path through the midpoints of the polygon edges. Each vertex is either a corner or is smoothed by a cubic Bézier, depending on its alpha value as defined by potrace, which is low for obtuse angles and high for sharp angles.
+func smooth(vertices []canvas.Point, alphaMax float64) *canvas.Path {
+	n := len(vertices)
+	mid := func(i int) canvas.Point {
+		return vertices[i%n].Interpolate(vertices[(i+1)%n], 0.5)
+	}
+
+	path := &canvas.Path{}
+	start := mid(n - 1)
+	path.MoveTo(start.X, start.Y)
+	for j := 0; j < n; j++ {
+		i, k := (j+n-1)%n, (j+1)%n
+		v0, v1, v2 := vertices[i], vertices[j], vertices[k]
+
+		alpha := 4.0 / 3.0
+		d := v2.Sub(v0)
+		if denom := math.Abs(d.X) + math.Abs(d.Y); denom != 0.0 {
+			// distance of v1 to the line v0-v2 relative to the L1 length of v0-v2
+			dd := math.Abs(v1.Sub(v0).PerpDot(d)) / denom
+			alpha = 0.0
+			if 1.0 < dd {
+				alpha = 1.0 - 1.0/dd
+			}
+			alpha /= 0.75
+		}
+
+		end := mid(j)
+		if alphaMax <= alpha {
+			path.LineTo(v1.X, v1.Y)
+			path.LineTo(end.X, end.Y)
+		} else {
+			alpha = math.Max(0.55, math.Min(1.0, alpha))
+			cp1 := v0.Interpolate(v1, 0.5+0.5*alpha)
+			cp2 := v2.Interpolate(v1, 0.5+0.5*alpha)
+			path.CubeTo(cp1.X, cp1.Y, cp2.X, cp2.Y, end.X, end.Y)
+		}
+	}
+	path.Close()
+	return path
+}
+
+func sign(i int) int {
+	if i < 0 {
+		return -1
+	} else if 0 < i {
+		return 1
+	}
+	return 0
+}
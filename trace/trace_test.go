@@ -0,0 +1,119 @@
+package trace
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func newImage(w, h int, f func(x, y int) bool) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if f(x, y) {
+				img.SetGray(x, y, color.Gray{0})
+			} else {
+				img.SetGray(x, y, color.Gray{255})
+			}
+		}
+	}
+	return img
+}
+
+func TestTraceSquare(t *testing.T) {
+	img := newImage(20, 20, func(x, y int) bool {
+		return 5 <= x && x < 15 && 2 <= y && y < 12
+	})
+	p := Trace(img, nil)
+	test.T(t, len(p.Split()), 1)
+
+	// corners are kept sharp
+	bounds := p.Bounds()
+	test.Float(t, bounds.X, 5.0)
+	test.Float(t, bounds.Y, 8.0)
+	test.Float(t, bounds.W, 10.0)
+	test.Float(t, bounds.H, 10.0)
+	test.That(t, p.Interior(10.0, 13.0, canvas.NonZero))
+	test.That(t, !p.Interior(10.0, 5.0, canvas.NonZero))
+
+	opts := DefaultOptions
+	opts.Invert = true
+	p = Trace(img, &opts)
+	test.That(t, !p.Interior(10.0, 13.0, canvas.NonZero))
+	test.That(t, p.Interior(10.0, 5.0, canvas.NonZero))
+}
+
+func TestTraceRing(t *testing.T) {
+	img := newImage(40, 40, func(x, y int) bool {
+		d := math.Hypot(float64(x)-19.5, float64(y)-19.5)
+		return 8.0 < d && d < 16.0
+	})
+	p := Trace(img, nil)
+	test.T(t, len(p.Split()), 2)
+	test.That(t, p.Interior(20.0, 8.0, canvas.NonZero))
+	test.That(t, !p.Interior(20.0, 20.0, canvas.NonZero))
+	test.That(t, !p.Interior(1.0, 1.0, canvas.NonZero))
+
+	// outline is smooth
+	bounds := p.Bounds()
+	test.That(t, math.Abs(bounds.W-32.0) < 1.0 && math.Abs(bounds.H-32.0) < 1.0, "bad bounds", bounds)
+	opts := DefaultOptions
+	opts.AlphaMax = 0.0
+	test.That(t, strings.Contains(p.String(), "C"), "expected curves")
+	test.That(t, !strings.Contains(Trace(img, &opts).String(), "C"), "expected polygon")
+}
+
+func TestTraceTurdSize(t *testing.T) {
+	img := newImage(20, 20, func(x, y int) bool {
+		return x == 2 && y == 2 || 10 <= x && x < 15 && 10 <= y && y < 15 && !(x == 12 && y == 12)
+	})
+	p := Trace(img, nil)
+	test.T(t, len(p.Split()), 1)
+	test.That(t, p.Interior(12.5, 7.5, canvas.NonZero))
+
+	opts := DefaultOptions
+	opts.TurdSize = 0
+	p = Trace(img, &opts)
+	test.T(t, len(p.Split()), 3)
+	test.That(t, !p.Interior(12.5, 7.5, canvas.NonZero))
+}
+
+func TestTraceColors(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			if x < 5 {
+				img.Set(x, y, canvas.Red)
+			} else if x < 15 {
+				img.Set(x, y, canvas.Blue)
+			}
+		}
+	}
+
+	paths, colors := TraceColors(img, nil)
+	test.T(t, len(paths), 2)
+	test.T(t, colors, []color.RGBA{canvas.Blue, canvas.Red})
+	test.That(t, paths[0].Interior(2.5, 5.0, canvas.NonZero), "largest layer covers the following layers")
+	test.That(t, paths[0].Interior(10.0, 5.0, canvas.NonZero))
+	test.That(t, !paths[0].Interior(17.5, 5.0, canvas.NonZero), "transparent pixels are not traced")
+	test.That(t, paths[1].Interior(2.5, 5.0, canvas.NonZero))
+	test.That(t, !paths[1].Interior(10.0, 5.0, canvas.NonZero))
+}
+
+func TestPosterize(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	img.Set(0, 0, color.RGBA{200, 100, 10, 255})
+	img.Set(1, 0, color.RGBA{190, 110, 20, 255})
+	img.Set(2, 0, color.RGBA{0, 0, 0, 0})
+
+	pal := Posterize(img, 2)
+	test.T(t, pal.At(0, 0), color.Color(color.RGBA{255, 0, 0, 255}))
+	test.T(t, pal.ColorIndexAt(0, 0), pal.ColorIndexAt(1, 0))
+	_, _, _, a := pal.At(2, 0).RGBA()
+	test.T(t, a, uint32(0))
+}
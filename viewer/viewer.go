@@ -0,0 +1,263 @@
+package viewer
+
+import (
+	"image"
+	"image/draw"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/rasterizer"
+	"golang.org/x/exp/shiny/driver"
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/mobile/event/key"
+	"golang.org/x/mobile/event/lifecycle"
+	"golang.org/x/mobile/event/mouse"
+	"golang.org/x/mobile/event/paint"
+	"golang.org/x/mobile/event/size"
+)
+
+// WatchInterval is the interval at which watched files are checked for modifications.
+var WatchInterval = 250 * time.Millisecond
+
+// zoomStep is the zoom factor applied for every scroll wheel step or +/- key press.
+const zoomStep = 1.25
+
+// Viewer displays a canvas in a window. The view can be panned by dragging with the mouse or using the arrow keys, and zoomed using the scroll wheel or the +/- keys. Pressing 0 resets the view, and Escape or q closes the window.
+type Viewer struct {
+	title         string
+	width, height int
+
+	mu     sync.Mutex
+	c      *canvas.Canvas
+	window screen.Window
+	closed chan struct{}
+
+	zoom     float64
+	pan      canvas.Point // in pixels
+	drag     bool
+	dragFrom canvas.Point
+}
+
+// New returns a new viewer that opens a window of width and height in pixels.
+func New(title string, width, height int) *Viewer {
+	return &Viewer{
+		title:  title,
+		width:  width,
+		height: height,
+		zoom:   1.0,
+		closed: make(chan struct{}),
+	}
+}
+
+// Show opens a window displaying the canvas and blocks until the window is closed. It must be called from the main goroutine.
+func Show(c *canvas.Canvas) error {
+	v := New("canvas", 800, 600)
+	v.Update(c)
+	return v.Run()
+}
+
+// Update replaces the displayed canvas and redraws the window. It is safe to call from any goroutine, which allows the figure to be regenerated while the window is open.
+func (v *Viewer) Update(c *canvas.Canvas) {
+	v.mu.Lock()
+	v.c = c
+	window := v.window
+	v.mu.Unlock()
+
+	if window != nil {
+		window.Send(paint.Event{})
+	}
+}
+
+// Watch calls draw and displays its canvas, and calls it again each time one of the given files is modified. This allows a figure to be reloaded live when its input data changes. Watching stops when the window is closed.
+func (v *Viewer) Watch(draw func() *canvas.Canvas, filenames ...string) {
+	v.Update(draw())
+	go func() {
+		modTimes := make([]time.Time, len(filenames))
+		for i, filename := range filenames {
+			if info, err := os.Stat(filename); err == nil {
+				modTimes[i] = info.ModTime()
+			}
+		}
+
+		ticker := time.NewTicker(WatchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-v.closed:
+				return
+			case <-ticker.C:
+			}
+
+			modified := false
+			for i, filename := range filenames {
+				if info, err := os.Stat(filename); err == nil && !info.ModTime().Equal(modTimes[i]) {
+					modTimes[i] = info.ModTime()
+					modified = true
+				}
+			}
+			if modified {
+				v.Update(draw())
+			}
+		}
+	}()
+}
+
+// Run opens the window and blocks until the window is closed. It must be called from the main goroutine.
+func (v *Viewer) Run() error {
+	var err error
+	driver.Main(func(s screen.Screen) {
+		var window screen.Window
+		window, err = s.NewWindow(&screen.NewWindowOptions{
+			Width:  v.width,
+			Height: v.height,
+			Title:  v.title,
+		})
+		if err != nil {
+			return
+		}
+		defer window.Release()
+
+		v.mu.Lock()
+		v.window = window
+		v.mu.Unlock()
+		defer func() {
+			v.mu.Lock()
+			v.window = nil
+			v.mu.Unlock()
+			select {
+			case <-v.closed:
+			default:
+				close(v.closed)
+			}
+		}()
+
+		var buffer screen.Buffer
+		defer func() {
+			if buffer != nil {
+				buffer.Release()
+			}
+		}()
+
+		for {
+			switch e := window.NextEvent().(type) {
+			case lifecycle.Event:
+				if e.To == lifecycle.StageDead {
+					return
+				}
+			case size.Event:
+				v.width, v.height = e.WidthPx, e.HeightPx
+			case paint.Event:
+				if buffer == nil || buffer.Size() != image.Pt(v.width, v.height) {
+					if buffer != nil {
+						buffer.Release()
+					}
+					if buffer, err = s.NewBuffer(image.Pt(v.width, v.height)); err != nil {
+						return
+					}
+				}
+				v.draw(buffer.RGBA())
+				window.Upload(image.Point{}, buffer, buffer.Bounds())
+				window.Publish()
+			case key.Event:
+				if e.Direction == key.DirRelease {
+					continue
+				}
+				switch {
+				case e.Code == key.CodeEscape || e.Rune == 'q':
+					return
+				case e.Rune == '+' || e.Rune == '=':
+					v.zoomAbout(zoomStep, float64(v.width)/2.0, float64(v.height)/2.0)
+				case e.Rune == '-':
+					v.zoomAbout(1.0/zoomStep, float64(v.width)/2.0, float64(v.height)/2.0)
+				case e.Rune == '0':
+					v.zoom = 1.0
+					v.pan = canvas.Point{}
+				case e.Code == key.CodeLeftArrow:
+					v.pan.X += float64(v.width) / 10.0
+				case e.Code == key.CodeRightArrow:
+					v.pan.X -= float64(v.width) / 10.0
+				case e.Code == key.CodeUpArrow:
+					v.pan.Y += float64(v.height) / 10.0
+				case e.Code == key.CodeDownArrow:
+					v.pan.Y -= float64(v.height) / 10.0
+				default:
+					continue
+				}
+				window.Send(paint.Event{})
+			case mouse.Event:
+				pos := canvas.Point{X: float64(e.X), Y: float64(e.Y)}
+				if e.Button == mouse.ButtonWheelUp && e.Direction == mouse.DirStep {
+					v.zoomAbout(zoomStep, pos.X, pos.Y)
+					window.Send(paint.Event{})
+				} else if e.Button == mouse.ButtonWheelDown && e.Direction == mouse.DirStep {
+					v.zoomAbout(1.0/zoomStep, pos.X, pos.Y)
+					window.Send(paint.Event{})
+				} else if e.Button == mouse.ButtonLeft && e.Direction == mouse.DirPress {
+					v.drag = true
+					v.dragFrom = pos
+				} else if e.Button == mouse.ButtonLeft && e.Direction == mouse.DirRelease {
+					v.drag = false
+				} else if v.drag && e.Direction == mouse.DirNone {
+					v.pan = v.pan.Add(pos.Sub(v.dragFrom))
+					v.dragFrom = pos
+					window.Send(paint.Event{})
+				}
+			case error:
+				err = e
+				return
+			}
+		}
+	})
+	return err
+}
+
+// zoomAbout zooms the view while keeping the window position (x,y) in pixels at the same place.
+func (v *Viewer) zoomAbout(factor, x, y float64) {
+	zoom := math.Max(math.Min(v.zoom*factor, 1000.0), 0.001)
+	factor = zoom / v.zoom
+	center := canvas.Point{X: float64(v.width) / 2.0, Y: float64(v.height) / 2.0}
+	pos := canvas.Point{X: x, Y: y}.Sub(center)
+	v.pan = pos.Sub(pos.Sub(v.pan).Mul(factor))
+	v.zoom = zoom
+}
+
+// draw renders the canvas centered in the image, with the canvas fit to the window size at a zoom of one.
+func (v *Viewer) draw(img *image.RGBA) {
+	draw.Draw(img, img.Bounds(), image.NewUniform(canvas.Lightgray), image.Point{}, draw.Src)
+
+	v.mu.Lock()
+	c := v.c
+	v.mu.Unlock()
+	if c == nil || c.W <= 0.0 || c.H <= 0.0 {
+		return
+	}
+
+	resolution, view := v.view(img.Bounds().Size(), c.W, c.H)
+	r := &renderer{rasterizer.New(img, resolution), view}
+	r.RenderPath(canvas.Rectangle(c.W, c.H).Transform(view), canvas.Style{FillColor: canvas.White}, canvas.Identity)
+	c.Render(r)
+}
+
+// view returns the resolution and the view matrix that places a canvas of w×h millimeters in an image of the given size in pixels. The view matrix maps canvas coordinates to image coordinates in millimeters with the Y axis pointing up. At a zoom of one the canvas is centered and fit to the image.
+func (v *Viewer) view(size image.Point, w, h float64) (canvas.DPMM, canvas.Matrix) {
+	resolution := v.zoom * math.Min(float64(size.X)/w, float64(size.Y)/h)
+
+	// position of the bottom-left corner of the canvas in pixels, with the Y axis pointing up
+	x := (float64(size.X)-w*resolution)/2.0 + v.pan.X
+	y := (float64(size.Y)-h*resolution)/2.0 - v.pan.Y
+	return canvas.DPMM(resolution), canvas.Identity.Translate(x/resolution, y/resolution)
+}
+
+// renderer is a rasterizer that translates all drawing operations by the view matrix.
+type renderer struct {
+	*rasterizer.Renderer
+	view canvas.Matrix
+}
+
+// View returns the view matrix, which is used by canvas.Canvas.Render.
+func (r *renderer) View() canvas.Matrix {
+	return r.view
+}
@@ -0,0 +1,46 @@
+package viewer
+
+import (
+	"image"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func TestViewerView(t *testing.T) {
+	v := New("test", 200, 100)
+	size := image.Pt(200, 100)
+
+	// fit and center
+	resolution, view := v.view(size, 10.0, 10.0)
+	test.Float(t, float64(resolution), 10.0)
+	test.T(t, view, canvas.Identity.Translate(5.0, 0.0))
+
+	// pan in pixels with the Y axis pointing down
+	v.pan = canvas.Point{X: 10.0, Y: 10.0}
+	_, view = v.view(size, 10.0, 10.0)
+	test.T(t, view, canvas.Identity.Translate(6.0, -1.0))
+}
+
+func TestViewerZoom(t *testing.T) {
+	v := New("test", 200, 100)
+	size := image.Pt(200, 100)
+
+	// position in canvas millimeters of the window pixel (x,y)
+	at := func(x, y float64) canvas.Point {
+		resolution, view := v.view(size, 10.0, 10.0)
+		return view.Inv().Dot(canvas.Point{X: x, Y: float64(size.Y) - y}.Div(float64(resolution)))
+	}
+
+	// zooming keeps the position under the cursor in place
+	for _, pos := range []canvas.Point{{X: 100.0, Y: 50.0}, {X: 0.0, Y: 0.0}, {X: 150.0, Y: 20.0}} {
+		before := at(pos.X, pos.Y)
+		v.zoomAbout(zoomStep, pos.X, pos.Y)
+		test.T(t, at(pos.X, pos.Y), before)
+	}
+
+	// zoom is limited
+	v.zoomAbout(1e9, 0.0, 0.0)
+	test.Float(t, v.zoom, 1000.0)
+}
@@ -0,0 +1,315 @@
+package xps
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/tdewolff/canvas"
+)
+
+// unitsPerMm is the number of XPS units (1/96 inch) per millimeter.
+const unitsPerMm = 96.0 / 25.4
+
+const xpsNamespace = "http://schemas.microsoft.com/xps/2005/06"
+
+type XPS struct {
+	zw            *zip.Writer
+	page          *bytes.Buffer
+	pageRels      []string
+	pages         int
+	width, height float64
+	images        map[image.Image]string
+	numImages     int
+	err           error
+}
+
+// New creates an XML Paper Specification renderer, which writes a single fixed document in an OPC (zip) package. Paths are written in units of 1/96 inch, while text is converted to paths.
+func New(w io.Writer, width, height float64) *XPS {
+	r := &XPS{
+		zw:     zip.NewWriter(w),
+		images: map[image.Image]string{},
+	}
+	r.NewPage(width, height)
+	return r
+}
+
+// NewPage starts adds a new page where further rendering will be written to
+func (r *XPS) NewPage(width, height float64) {
+	r.writePage()
+	r.pages++
+	r.width, r.height = width, height
+	r.pageRels = r.pageRels[:0]
+	r.page = &bytes.Buffer{}
+	fmt.Fprintf(r.page, `<FixedPage xmlns="%s" xmlns:x="http://schemas.microsoft.com/xps/2005/06/resourcedictionary-key" xml:lang="und" Width="%v" Height="%v">`, xpsNamespace, dec(width*unitsPerMm), dec(height*unitsPerMm))
+}
+
+func (r *XPS) writePage() {
+	if r.page == nil {
+		return
+	}
+	fmt.Fprintf(r.page, `</FixedPage>`)
+	r.writeFile(fmt.Sprintf("Documents/1/Pages/%d.fpage", r.pages), r.page.Bytes())
+	if 0 < len(r.pageRels) {
+		b := &bytes.Buffer{}
+		fmt.Fprintf(b, `<?xml version="1.0" encoding="UTF-8"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+		for i, target := range r.pageRels {
+			fmt.Fprintf(b, `<Relationship Id="R%d" Type="http://schemas.microsoft.com/xps/2005/06/required-resource" Target="%s"/>`, i, target)
+		}
+		fmt.Fprintf(b, `</Relationships>`)
+		r.writeFile(fmt.Sprintf("Documents/1/Pages/_rels/%d.fpage.rels", r.pages), b.Bytes())
+	}
+	r.page = nil
+}
+
+func (r *XPS) writeFile(name string, b []byte) {
+	if r.err != nil {
+		return
+	}
+	var w io.Writer
+	if w, r.err = r.zw.Create(name); r.err == nil {
+		_, r.err = w.Write(b)
+	}
+}
+
+// Close writes the last page and the package structure.
+func (r *XPS) Close() error {
+	r.writePage()
+
+	r.writeFile("[Content_Types].xml", []byte(`<?xml version="1.0" encoding="UTF-8"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`+
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`+
+		`<Default Extension="fdseq" ContentType="application/vnd.ms-package.xps-fixeddocumentsequence+xml"/>`+
+		`<Default Extension="fdoc" ContentType="application/vnd.ms-package.xps-fixeddocument+xml"/>`+
+		`<Default Extension="fpage" ContentType="application/vnd.ms-package.xps-fixedpage+xml"/>`+
+		`<Default Extension="png" ContentType="image/png"/>`+
+		`</Types>`))
+	r.writeFile("_rels/.rels", []byte(`<?xml version="1.0" encoding="UTF-8"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`+
+		`<Relationship Id="R0" Type="http://schemas.microsoft.com/xps/2005/06/fixedrepresentation" Target="/FixedDocumentSequence.fdseq"/>`+
+		`</Relationships>`))
+	r.writeFile("FixedDocumentSequence.fdseq", []byte(`<FixedDocumentSequence xmlns="`+xpsNamespace+`"><DocumentReference Source="/Documents/1/FixedDocument.fdoc"/></FixedDocumentSequence>`))
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, `<FixedDocument xmlns="%s">`, xpsNamespace)
+	for i := 1; i <= r.pages; i++ {
+		fmt.Fprintf(b, `<PageContent Source="Pages/%d.fpage"/>`, i)
+	}
+	fmt.Fprintf(b, `</FixedDocument>`)
+	r.writeFile("Documents/1/FixedDocument.fdoc", b.Bytes())
+
+	if r.err != nil {
+		return r.err
+	}
+	return r.zw.Close()
+}
+
+func (r *XPS) Size() (float64, float64) {
+	return r.width, r.height
+}
+
+// color returns the color in #AARRGGBB notation, which is not premultiplied.
+func (r *XPS) color(col color.RGBA) string {
+	if col.A == 255 {
+		return fmt.Sprintf("#%02X%02X%02X", col.R, col.G, col.B)
+	}
+	A := float64(col.A) / 255.0
+	R := uint8(math.Round(float64(col.R) / A))
+	G := uint8(math.Round(float64(col.G) / A))
+	B := uint8(math.Round(float64(col.B) / A))
+	return fmt.Sprintf("#%02X%02X%02X%02X", col.A, R, G, B)
+}
+
+func (r *XPS) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix) {
+	if style.FillPaint != nil || style.StrokePaint != nil {
+		canvas.RenderPaintAsImage(r, path, style, m)
+		return
+	}
+
+	fill := style.HasFill()
+	stroke := style.HasStroke()
+	if path.Empty() || !fill && !stroke {
+		return
+	}
+
+	// XPS doesn't support the arcs joiner, miter joiner (not clipped), or miter joiner (clipped) with non-bevel fallback
+	strokeUnsupported := false
+	if _, ok := style.StrokeJoiner.(canvas.ArcsJoiner); ok {
+		strokeUnsupported = true
+	} else if miter, ok := style.StrokeJoiner.(canvas.MiterJoiner); ok {
+		if math.IsNaN(miter.Limit) {
+			strokeUnsupported = true
+		} else if _, ok := miter.GapJoiner.(canvas.BevelJoiner); !ok {
+			strokeUnsupported = true
+		}
+	}
+
+	path = path.Transform(m)
+	if fill || !strokeUnsupported {
+		fmt.Fprintf(r.page, `<Path Data="%s"`, r.pathData(path, style.FillRule))
+		if fill {
+			fmt.Fprintf(r.page, ` Fill="%s"`, r.color(style.FillColor))
+		}
+		if stroke && !strokeUnsupported {
+			width := style.StrokeWidth * unitsPerMm
+			fmt.Fprintf(r.page, ` Stroke="%s" StrokeThickness="%v"`, r.color(style.StrokeColor), dec(width))
+
+			capper := "Flat"
+			if _, ok := style.StrokeCapper.(canvas.RoundCapper); ok {
+				capper = "Round"
+			} else if _, ok := style.StrokeCapper.(canvas.SquareCapper); ok {
+				capper = "Square"
+			} else if _, ok := style.StrokeCapper.(canvas.ButtCapper); !ok {
+				panic("XPS: line cap not support")
+			}
+			if capper != "Flat" {
+				fmt.Fprintf(r.page, ` StrokeStartLineCap="%s" StrokeEndLineCap="%s" StrokeDashCap="%s"`, capper, capper, capper)
+			}
+
+			if _, ok := style.StrokeJoiner.(canvas.BevelJoiner); ok {
+				fmt.Fprintf(r.page, ` StrokeLineJoin="Bevel"`)
+			} else if _, ok := style.StrokeJoiner.(canvas.RoundJoiner); ok {
+				fmt.Fprintf(r.page, ` StrokeLineJoin="Round"`)
+			} else if miter, ok := style.StrokeJoiner.(canvas.MiterJoiner); ok {
+				// a miter line join is the default
+				if limit := miter.Limit * 2.0 / style.StrokeWidth; !canvas.Equal(limit, 10.0) {
+					fmt.Fprintf(r.page, ` StrokeMiterLimit="%v"`, dec(math.Max(1.0, limit)))
+				}
+			} else {
+				panic("XPS: line join not support")
+			}
+
+			if 0 < len(style.Dashes) {
+				// dashes are relative to the stroke thickness
+				dashes := style.Dashes
+				if len(dashes)%2 == 1 {
+					dashes = append(append([]float64{}, dashes...), dashes...)
+				}
+				fmt.Fprintf(r.page, ` StrokeDashArray="`)
+				for i, dash := range dashes {
+					if i != 0 {
+						fmt.Fprintf(r.page, " ")
+					}
+					fmt.Fprintf(r.page, "%v", dec(dash/style.StrokeWidth))
+				}
+				fmt.Fprintf(r.page, `"`)
+				if style.DashOffset != 0.0 {
+					fmt.Fprintf(r.page, ` StrokeDashOffset="%v"`, dec(style.DashOffset/style.StrokeWidth))
+				}
+			}
+		}
+		fmt.Fprintf(r.page, `/>`)
+	}
+
+	if stroke && strokeUnsupported {
+		// stroke settings unsupported by XPS, draw stroke explicitly
+		if 0 < len(style.Dashes) {
+			path = path.Dash(style.DashOffset, style.Dashes...)
+		}
+		path = path.Stroke(style.StrokeWidth, style.StrokeCapper, style.StrokeJoiner)
+		fmt.Fprintf(r.page, `<Path Data="%s" Fill="%s"/>`, r.pathData(path, canvas.NonZero), r.color(style.StrokeColor))
+	}
+}
+
+// pathData returns the path in the abbreviated geometry syntax in page coordinates.
+func (r *XPS) pathData(path *canvas.Path, fillRule canvas.FillRule) string {
+	path = path.Transform(canvas.Identity.Scale(unitsPerMm, unitsPerMm).ReflectYAbout(r.height / 2.0))
+	sb := &strings.Builder{}
+	if fillRule == canvas.NonZero {
+		sb.WriteString("F1")
+	} else {
+		sb.WriteString("F0")
+	}
+	path.Iterate(func(start, end canvas.Point) {
+		fmt.Fprintf(sb, " M%v,%v", dec(end.X), dec(end.Y))
+	}, func(start, end canvas.Point) {
+		fmt.Fprintf(sb, " L%v,%v", dec(end.X), dec(end.Y))
+	}, func(start, cp, end canvas.Point) {
+		fmt.Fprintf(sb, " Q%v,%v %v,%v", dec(cp.X), dec(cp.Y), dec(end.X), dec(end.Y))
+	}, func(start, cp1, cp2, end canvas.Point) {
+		fmt.Fprintf(sb, " C%v,%v %v,%v %v,%v", dec(cp1.X), dec(cp1.Y), dec(cp2.X), dec(cp2.Y), dec(end.X), dec(end.Y))
+	}, func(start canvas.Point, rx, ry, rot float64, large, sweep bool, end canvas.Point) {
+		iLarge := 0
+		if large {
+			iLarge = 1
+		}
+		iSweep := 0
+		if sweep {
+			iSweep = 1
+		}
+		fmt.Fprintf(sb, " A%v,%v %v %v %v %v,%v", dec(rx), dec(ry), dec(rot), iLarge, iSweep, dec(end.X), dec(end.Y))
+	}, func(start, end canvas.Point) {
+		sb.WriteString(" Z")
+	})
+	return sb.String()
+}
+
+func (r *XPS) RenderText(text *canvas.Text, m canvas.Matrix) {
+	// TODO: (XPS) write text natively using Glyphs with obfuscated fonts
+	canvas.RenderTextAsPath(r, text, m)
+}
+
+func (r *XPS) RenderImage(img image.Image, m canvas.Matrix) {
+	size := img.Bounds().Size()
+	if size.X == 0 || size.Y == 0 {
+		return
+	}
+
+	// only images of a comparable type can be used as map keys, others such as struct values containing slices are written for every use
+	comparable := reflect.TypeOf(img).Comparable()
+	name, ok := "", false
+	if comparable {
+		name, ok = r.images[img]
+	}
+	if !ok {
+		buf := &bytes.Buffer{}
+		if err := png.Encode(buf, img); err != nil {
+			if r.err == nil {
+				r.err = err
+			}
+			return
+		}
+		r.numImages++
+		name = fmt.Sprintf("/Resources/Images/%d.png", r.numImages)
+		r.writeFile(name[1:], buf.Bytes())
+		if comparable {
+			r.images[img] = name
+		}
+	}
+	hasRel := false
+	for _, target := range r.pageRels {
+		if target == name {
+			hasRel = true
+			break
+		}
+	}
+	if !hasRel {
+		r.pageRels = append(r.pageRels, name)
+	}
+
+	// map the image with its origin at the top-left to the page, an image of 96 DPI has a size of one unit per pixel
+	w, h := float64(size.X), float64(size.Y)
+	fmt.Fprintf(r.page, `<Path Data="M0,0 L%v,0 L%v,%v L0,%v Z" RenderTransform="%v,%v,%v,%v,%v,%v"><Path.Fill><ImageBrush ImageSource="%s" Viewbox="0,0,%v,%v" ViewboxUnits="Absolute" Viewport="0,0,%v,%v" ViewportUnits="Absolute" TileMode="None"/></Path.Fill></Path>`,
+		dec(w), dec(w), dec(h), dec(h),
+		dec(m[0][0]*unitsPerMm), dec(-m[1][0]*unitsPerMm), dec(-m[0][1]*unitsPerMm), dec(m[1][1]*unitsPerMm),
+		dec((m[0][1]*h+m[0][2])*unitsPerMm), dec((r.height-m[1][1]*h-m[1][2])*unitsPerMm),
+		name, dec(w), dec(h), dec(w), dec(h))
+}
+
+type dec float64
+
+func (f dec) String() string {
+	s := fmt.Sprintf("%.*f", canvas.Precision, f)
+	if strings.IndexByte(s, '.') != -1 {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
@@ -0,0 +1,116 @@
+package xps
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func readFiles(t *testing.T, b []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	test.Error(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		test.Error(t, err)
+		data, err := ioutil.ReadAll(rc)
+		test.Error(t, err)
+		files[f.Name] = string(data)
+	}
+	return files
+}
+
+func TestXPS(t *testing.T) {
+	c := canvas.New(25.4, 25.4)
+	ctx := canvas.NewContext(c)
+	ctx.SetFillColor(canvas.Red)
+	ctx.DrawPath(0, 0, canvas.MustParseSVG("M0 0L10 0A5 5 0 0 1 0 10z"))
+
+	buf := &bytes.Buffer{}
+	test.Error(t, Writer(buf, c))
+	files := readFiles(t, buf.Bytes())
+	test.T(t, len(files), 5)
+	test.That(t, strings.Contains(files["[Content_Types].xml"], `Extension="fpage"`), "missing fixed page content type")
+	test.That(t, strings.Contains(files["_rels/.rels"], `Target="/FixedDocumentSequence.fdseq"`), "missing package relationship")
+	test.That(t, strings.Contains(files["FixedDocumentSequence.fdseq"], `Source="/Documents/1/FixedDocument.fdoc"`), "missing document reference")
+	test.That(t, strings.Contains(files["Documents/1/FixedDocument.fdoc"], `<PageContent Source="Pages/1.fpage"/>`), "missing page content")
+	test.String(t, files["Documents/1/Pages/1.fpage"], `<FixedPage xmlns="http://schemas.microsoft.com/xps/2005/06" xmlns:x="http://schemas.microsoft.com/xps/2005/06/resourcedictionary-key" xml:lang="und" Width="96" Height="96"><Path Data="F1 M0,96 L37.79527559,96 A26.72529567,26.72529567 0 0 0 0,58.20472441 Z" Fill="#FF0000"/></FixedPage>`)
+}
+
+func TestXPSStroke(t *testing.T) {
+	buf := &bytes.Buffer{}
+	xps := New(buf, 25.4, 25.4)
+	style := canvas.DefaultStyle
+	style.FillColor = canvas.Transparent
+	style.StrokeColor = canvas.Blue
+	style.StrokeWidth = 2.0
+	style.StrokeCapper = canvas.RoundCap
+	style.StrokeJoiner = canvas.BevelJoin
+	style.Dashes = []float64{4.0, 2.0}
+	xps.RenderPath(canvas.MustParseSVG("M0 0L10 0"), style, canvas.Identity)
+	style.StrokeJoiner = canvas.ArcsJoin
+	xps.RenderPath(canvas.MustParseSVG("M0 0L10 0"), style, canvas.Identity)
+	test.Error(t, xps.Close())
+
+	page := readFiles(t, buf.Bytes())["Documents/1/Pages/1.fpage"]
+	test.That(t, strings.Contains(page, `<Path Data="F1 M0,96 L37.79527559,96" Stroke="#0000FF" StrokeThickness="7.55905512" StrokeStartLineCap="Round" StrokeEndLineCap="Round" StrokeDashCap="Round" StrokeLineJoin="Bevel" StrokeDashArray="2 1"/>`), page)
+	test.T(t, strings.Count(page, `Fill="#0000FF"`), 1, "unsupported stroke is filled")
+
+	// odd dash arrays are repeated without writing into the style's backing array
+	dashes := make([]float64, 1, 2)
+	dashes[0] = 4.0
+	style.StrokeJoiner = canvas.BevelJoin
+	style.Dashes = dashes
+	xps = New(&bytes.Buffer{}, 25.4, 25.4)
+	xps.RenderPath(canvas.MustParseSVG("M0 0L10 0"), style, canvas.Identity)
+	test.T(t, dashes[:2], []float64{4.0, 0.0})
+}
+
+func TestXPSImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 3))
+
+	buf := &bytes.Buffer{}
+	xps := New(buf, 25.4, 25.4)
+	xps.RenderImage(img, canvas.Identity.Translate(10, 10))
+	xps.RenderImage(img, canvas.Identity)
+	xps.NewPage(50, 50)
+	xps.RenderImage(img, canvas.Identity)
+	test.Error(t, xps.Close())
+
+	files := readFiles(t, buf.Bytes())
+	test.That(t, strings.HasPrefix(files["Resources/Images/1.png"], "\x89PNG"), "missing image")
+	test.T(t, strings.Count(files["Documents/1/Pages/_rels/1.fpage.rels"], "<Relationship "), 1)
+	test.T(t, strings.Count(files["Documents/1/Pages/_rels/2.fpage.rels"], "<Relationship "), 1)
+	test.That(t, strings.Contains(files["Documents/1/FixedDocument.fdoc"], `<PageContent Source="Pages/2.fpage"/>`), "missing second page")
+	test.That(t, strings.Contains(files["Documents/1/Pages/1.fpage"], `RenderTransform="3.77952756,0,0,3.77952756,37.79527559,46.86614173"`), files["Documents/1/Pages/1.fpage"])
+}
+
+type sliceImage struct {
+	pix []color.Gray
+}
+
+func (img sliceImage) ColorModel() color.Model { return color.GrayModel }
+func (img sliceImage) Bounds() image.Rectangle { return image.Rect(0, 0, len(img.pix), 1) }
+func (img sliceImage) At(x, y int) color.Color { return img.pix[x] }
+
+func TestXPSImageUncomparable(t *testing.T) {
+	img := sliceImage{[]color.Gray{{0}, {255}}}
+
+	buf := &bytes.Buffer{}
+	xps := New(buf, 25.4, 25.4)
+	xps.RenderImage(img, canvas.Identity)
+	xps.RenderImage(img, canvas.Identity)
+	test.Error(t, xps.Close())
+
+	files := readFiles(t, buf.Bytes())
+	test.That(t, strings.HasPrefix(files["Resources/Images/1.png"], "\x89PNG"), "missing image")
+	test.That(t, strings.HasPrefix(files["Resources/Images/2.png"], "\x89PNG"), "missing image")
+	test.T(t, strings.Count(files["Documents/1/Pages/_rels/1.fpage.rels"], "<Relationship "), 2)
+}
@@ -0,0 +1,14 @@
+package xps
+
+import (
+	"io"
+
+	"github.com/tdewolff/canvas"
+)
+
+// Writer writes the canvas as an XPS file.
+func Writer(w io.Writer, c *canvas.Canvas) error {
+	xps := New(w, c.W, c.H)
+	c.Render(xps)
+	return xps.Close()
+}